@@ -0,0 +1,177 @@
+package aa
+
+import "iter"
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Allocator is an immutable best-fit allocator of address ranges.
+//
+// The zero value for Allocator has no free space;
+// use Free to make ranges available for allocation.
+//
+// Free ranges are tracked by address, to coalesce neighbours on Free,
+// and by size (then address), to find the best fit on Alloc.
+// All operations are O(log n), for n free ranges.
+type Allocator[A Integer] struct {
+	byAddr *Tree[A, A]                  // start → end
+	bySize *Tree[A, *Tree[A, struct{}]] // size → starts
+	free   A                            // total free space
+}
+
+// AllocatorStats reports the free space of an Allocator.
+type AllocatorStats[A Integer] struct {
+	Free    A   // total free space
+	Largest A   // largest free range
+	Ranges  int // number of free ranges
+	// Fragmentation is the fraction of free space
+	// outside the largest free range:
+	// 0 if free space is contiguous, approaching 1 as it fragments.
+	Fragmentation float64
+}
+
+// NewAllocator creates an allocator
+// with the range [base, base+size) free.
+func NewAllocator[A Integer](base, size A) Allocator[A] {
+	var a Allocator[A]
+	return a.Free(base, size)
+}
+
+// FreeRanges returns an ascending iterator for the free ranges
+// of this allocator.
+func (a Allocator[A]) FreeRanges() iter.Seq[Interval[A]] {
+	return func(yield func(Interval[A]) bool) {
+		for lo, hi := range a.byAddr.Ascend() {
+			if !yield(Interval[A]{lo, hi}) {
+				return
+			}
+		}
+	}
+}
+
+// IsFree reports whether every address of [addr, addr+size) is free.
+func (a Allocator[A]) IsFree(addr, size A) bool {
+	node := a.byAddr.Floor(addr)
+	return node != nil && addr < node.value && size <= node.value-addr
+}
+
+// Alloc returns a modified allocator with size addresses allocated
+// from the smallest free range that fits them (lowest address first),
+// and the start of the allocated range;
+// ok is false if no free range fits.
+//
+// Note: Alloc panics if size is not positive.
+func (a Allocator[A]) Alloc(size A) (_ Allocator[A], addr A, ok bool) {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	node := a.bySize.Ceil(size)
+	if node == nil {
+		return a, 0, false
+	}
+	addr = node.value.Min().key
+	end := addr + node.key
+	a = a.remove(addr, end)
+	if rest := addr + size; rest != end {
+		a = a.insert(rest, end)
+	}
+	return a, addr, true
+}
+
+// AllocAt returns a (possibly) modified allocator
+// with [addr, addr+size) allocated;
+// ok is false if any address in the range is not free.
+//
+// Note: AllocAt panics if size is not positive.
+func (a Allocator[A]) AllocAt(addr, size A) (_ Allocator[A], ok bool) {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	if !a.IsFree(addr, size) {
+		return a, false
+	}
+	node := a.byAddr.Floor(addr)
+	lo, hi := node.key, node.value
+	a = a.remove(lo, hi)
+	if lo != addr {
+		a = a.insert(lo, addr)
+	}
+	if end := addr + size; end != hi {
+		a = a.insert(end, hi)
+	}
+	return a, true
+}
+
+// Free returns a modified allocator with [addr, addr+size) free,
+// coalesced with any adjacent free ranges.
+// The range need not have been allocated from this allocator,
+// so Free also adds space to an allocator.
+//
+// Note: Free panics if size is not positive,
+// if the range overflows, or if any address in it is already free.
+func (a Allocator[A]) Free(addr, size A) Allocator[A] {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	end := addr + size
+	if end < addr {
+		panic("range overflows")
+	}
+	prev := a.byAddr.Floor(addr)
+	next := a.byAddr.Ceil(addr)
+	if prev != nil && addr < prev.value || next != nil && next.key < end {
+		panic("range is already free")
+	}
+
+	if prev != nil && prev.value == addr {
+		a = a.remove(prev.key, prev.value)
+		addr = prev.key
+	}
+	if next != nil && next.key == end {
+		a = a.remove(next.key, next.value)
+		end = next.value
+	}
+	return a.insert(addr, end)
+}
+
+// Stats reports the free space of this allocator.
+func (a Allocator[A]) Stats() AllocatorStats[A] {
+	stats := AllocatorStats[A]{
+		Free:   a.free,
+		Ranges: a.byAddr.Len(),
+	}
+	if node := a.bySize.Max(); node != nil {
+		stats.Largest = node.key
+	}
+	if stats.Free > 0 {
+		stats.Fragmentation = 1 - float64(stats.Largest)/float64(stats.Free)
+	}
+	return stats
+}
+
+func (a Allocator[A]) insert(lo, hi A) Allocator[A] {
+	return Allocator[A]{
+		byAddr: a.byAddr.Put(lo, hi),
+		bySize: a.bySize.Patch(hi-lo, func(node *Tree[A, *Tree[A, struct{}]]) (*Tree[A, struct{}], bool) {
+			var starts *Tree[A, struct{}]
+			if node != nil {
+				starts = node.value
+			}
+			return starts.Add(lo), true
+		}),
+		free: a.free + (hi - lo),
+	}
+}
+
+func (a Allocator[A]) remove(lo, hi A) Allocator[A] {
+	bySize := a.bySize
+	if starts := bySize.lookup(hi - lo).value.Delete(lo); starts == nil {
+		bySize = bySize.Delete(hi - lo)
+	} else {
+		bySize = bySize.Put(hi-lo, starts)
+	}
+	return Allocator[A]{a.byAddr.Delete(lo), bySize, a.free - (hi - lo)}
+}
@@ -0,0 +1,171 @@
+package aa
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a := NewAllocator[uint16](1000, 100)
+
+	a, p1, ok := a.Alloc(10)
+	if !ok || p1 != 1000 {
+		t.Fatal(p1, ok)
+	}
+	a, p2, _ := a.Alloc(20)
+	a, p3, _ := a.Alloc(30)
+	if p2 != 1010 || p3 != 1030 {
+		t.Fatal(p2, p3)
+	}
+	snapshot := a
+
+	// Best fit prefers the smaller hole.
+	a = a.Free(p1, 10).Free(p3, 30)
+	if s := a.Stats(); s.Free != 80 || s.Largest != 70 || s.Ranges != 2 {
+		t.Error(s)
+	}
+	a, p, _ := a.Alloc(5)
+	if p != 1000 {
+		t.Error(p)
+	}
+	a = a.Free(p, 5)
+
+	// Freeing p2 coalesces everything.
+	a = a.Free(p2, 20)
+	if got := slices.Collect(a.FreeRanges()); !slices.Equal(got, []Interval[uint16]{{1000, 1100}}) {
+		t.Error(got)
+	}
+	if s := a.Stats(); s.Free != 100 || s.Fragmentation != 0 {
+		t.Error(s)
+	}
+
+	if _, _, ok := a.Alloc(101); ok {
+		t.Error()
+	}
+	if _, ok := a.AllocAt(1050, 51); ok {
+		t.Error()
+	}
+	a, ok = a.AllocAt(1040, 20)
+	if !ok || a.IsFree(1059, 1) || !a.IsFree(1060, 40) {
+		t.Error()
+	}
+	if s := a.Stats(); s.Largest != 40 || s.Fragmentation != 0.5 {
+		t.Error(s)
+	}
+
+	// Snapshots are immutable.
+	if s := snapshot.Stats(); s.Free != 40 || s.Ranges != 1 {
+		t.Error(s)
+	}
+
+	for range a.FreeRanges() {
+		break
+	}
+	if s := (Allocator[int]{}).Stats(); s != (AllocatorStats[int]{}) {
+		t.Error(s)
+	}
+
+	for _, f := range []func(){
+		func() { a.Alloc(0) },
+		func() { a.AllocAt(0, 0) },
+		func() { a.Free(0, 0) },
+		func() { a.Free(65535, 2) },
+		func() { a.Free(1030, 20) },
+		func() { a.Free(1059, 2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestAllocator_random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	check := func(a Allocator[int], free []bool) {
+		t.Helper()
+		a.byAddr.check()
+		a.bySize.check()
+		last := -1
+		n := 0
+		for i := range a.FreeRanges() {
+			if i.Lo <= last {
+				t.Fatalf("uncoalesced %v after %d", i, last)
+			}
+			n += i.Hi - i.Lo
+			last = i.Hi
+		}
+		for x := range free {
+			if a.IsFree(x, 1) != free[x] {
+				t.Fatal(x)
+			}
+		}
+		if s := a.Stats(); s.Free != n || s.Ranges != a.byAddr.Len() {
+			t.Fatal(s)
+		}
+	}
+
+	free := make([]bool, 256)
+	for i := range free {
+		free[i] = true
+	}
+	a := NewAllocator(0, len(free))
+	type block struct{ addr, size int }
+	var used []block
+
+	for range 2000 {
+		switch size := r.Intn(16) + 1; {
+		case len(used) > 0 && r.Intn(2) == 0:
+			i := r.Intn(len(used))
+			b := used[i]
+			used = slices.Delete(used, i, i+1)
+			a = a.Free(b.addr, b.size)
+			for x := b.addr; x < b.addr+b.size; x++ {
+				free[x] = true
+			}
+		case r.Intn(2) == 0:
+			addr := r.Intn(len(free) - size)
+			want := !slices.Contains(free[addr:addr+size], false)
+			var ok bool
+			a, ok = a.AllocAt(addr, size)
+			if ok != want {
+				t.Fatal(addr, size, ok)
+			}
+			if ok {
+				used = append(used, block{addr, size})
+				for x := addr; x < addr+size; x++ {
+					free[x] = false
+				}
+			}
+		default:
+			// The best fit is the smallest fitting range.
+			best := 0
+			for i := range a.FreeRanges() {
+				if n := i.Hi - i.Lo; n >= size && (best == 0 || n < best) {
+					best = n
+				}
+			}
+			b, addr, ok := a.Alloc(size)
+			if ok != (best != 0) {
+				t.Fatal(size, ok)
+			}
+			if ok {
+				if node := a.byAddr.lookup(addr); node == nil || node.value-addr != best {
+					t.Fatal("not best fit", addr, size, best)
+				}
+				used = append(used, block{addr, size})
+				for x := addr; x < addr+size; x++ {
+					free[x] = false
+				}
+			}
+			a = b
+		}
+		check(a, free)
+	}
+}
@@ -0,0 +1,142 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Bag is an immutable multiset: a sorted set of keys,
+// each with a positive count of occurrences (its multiplicity).
+//
+// The zero value for Bag is the empty bag.
+//
+// Nodes track the total count of their subtree,
+// so order statistics that account for multiplicity are O(log n).
+type Bag[K cmp.Ordered] struct {
+	tree *Tree[K, weighted[struct{}]]
+}
+
+// Len returns the number of distinct keys in this bag.
+func (b Bag[K]) Len() int {
+	return b.tree.Len()
+}
+
+// Total returns the total count of all keys in this bag.
+func (b Bag[K]) Total() int {
+	return totalWeight(b.tree)
+}
+
+// Count returns the count of key in this bag.
+func (b Bag[K]) Count(key K) int {
+	if node := b.tree.lookup(key); node != nil {
+		return node.value.weight
+	}
+	return 0
+}
+
+// Inc returns a (possibly) modified bag
+// with the count of key increased by n.
+//
+// Note: Inc panics if n is negative.
+func (b Bag[K]) Inc(key K, n int) Bag[K] {
+	if n < 0 {
+		panic("negative count")
+	}
+	if n == 0 {
+		return b
+	}
+	return Bag[K]{reweigh(b.tree.Patch(key, func(node *Tree[K, weighted[struct{}]]) (weighted[struct{}], bool) {
+		if node != nil {
+			n += node.value.weight
+		}
+		return weighted[struct{}]{weight: n}, true
+	}))}
+}
+
+// Dec returns a (possibly) modified bag
+// with the count of key decreased by n,
+// and key removed once its count reaches zero.
+//
+// Note: Dec panics if n is negative.
+func (b Bag[K]) Dec(key K, n int) Bag[K] {
+	if n < 0 {
+		panic("negative count")
+	}
+	node := b.tree.lookup(key)
+	switch {
+	case n == 0 || node == nil:
+		return b
+	case n >= node.value.weight:
+		return Bag[K]{reweigh(b.tree.Delete(key))}
+	default:
+		return Bag[K]{reweigh(b.tree.Put(key, weighted[struct{}]{weight: node.value.weight - n}))}
+	}
+}
+
+// All returns an ascending iterator for the keys in this bag, and their counts.
+func (b Bag[K]) All() iter.Seq2[K, int] {
+	return func(yield func(K, int) bool) {
+		for k, w := range b.tree.Ascend() {
+			if !yield(k, w.weight) {
+				return
+			}
+		}
+	}
+}
+
+// Rank returns the total count of keys in this bag less than key.
+func (b Bag[K]) Rank(key K) int {
+	return weightRank(b.tree, key)
+}
+
+// Select returns the key at index i of this bag,
+// where each key occupies as many indexes as its count;
+// ok is false if i is out of range.
+func (b Bag[K]) Select(i int) (key K, ok bool) {
+	if node, _ := weightSelect(b.tree, i); node != nil {
+		return node.key, true
+	}
+	return
+}
+
+// Sum returns the bag sum of b and o:
+// counts are added.
+func (b Bag[K]) Sum(o Bag[K]) Bag[K] {
+	return Bag[K]{reweigh(UnionFunc(b.tree, o.tree, func(_ K, w1, w2 weighted[struct{}]) weighted[struct{}] {
+		return weighted[struct{}]{weight: w1.weight + w2.weight}
+	}))}
+}
+
+// Union returns the bag union of b and o:
+// the maximum of their counts.
+func (b Bag[K]) Union(o Bag[K]) Bag[K] {
+	return Bag[K]{reweigh(UnionFunc(b.tree, o.tree, func(_ K, w1, w2 weighted[struct{}]) weighted[struct{}] {
+		return weighted[struct{}]{weight: max(w1.weight, w2.weight)}
+	}))}
+}
+
+// Intersection returns the bag intersection of b and o:
+// the minimum of their counts.
+func (b Bag[K]) Intersection(o Bag[K]) Bag[K] {
+	return Bag[K]{reweigh(IntersectionFunc(b.tree, o.tree, func(_ K, w1, w2 weighted[struct{}]) weighted[struct{}] {
+		return weighted[struct{}]{weight: min(w1.weight, w2.weight)}
+	}))}
+}
+
+// WeightedQuantile returns the q-quantile of the keys in b,
+// for 0 ≤ q ≤ 1, using the given interpolation method,
+// and counting each key as many times as its count.
+// A NaN key is ignored.
+//
+// Note: the quantile of an empty bag,
+// or for q outside [0, 1], is NaN.
+func WeightedQuantile[K Number](b Bag[K], q float64, method Interpolation) float64 {
+	off := 0
+	if node := nan(b.tree); node != nil {
+		off = node.value.weight
+	}
+	return quantile(b.Total()-off, q, method, func(i int) float64 {
+		node, _ := weightSelect(b.tree, off+i)
+		return float64(node.key)
+	})
+}
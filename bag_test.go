@@ -0,0 +1,127 @@
+package aa
+
+import (
+	"maps"
+	"math"
+	"testing"
+)
+
+func TestBag(t *testing.T) {
+	var b Bag[string]
+	if b.Len() != 0 || b.Total() != 0 || b.Count("a") != 0 {
+		t.Error()
+	}
+
+	b = b.Inc("b", 2).Inc("a", 1).Inc("c", 3).Inc("b", 1).Inc("d", 0)
+	b.tree.check()
+	checkWeights(t, b.tree)
+
+	if b.Len() != 3 || b.Total() != 7 {
+		t.Error(b.Len(), b.Total())
+	}
+	if n := b.Count("b"); n != 3 {
+		t.Error(n)
+	}
+	if m := maps.Collect(b.All()); !maps.Equal(m, map[string]int{"a": 1, "b": 3, "c": 3}) {
+		t.Error(m)
+	}
+
+	// a b b b c c c
+	for i, want := range []string{"a", "b", "b", "b", "c", "c", "c"} {
+		if k, ok := b.Select(i); !ok || k != want {
+			t.Error(i, k, ok)
+		}
+	}
+	if _, ok := b.Select(7); ok {
+		t.Error()
+	}
+	if i := b.Rank("b"); i != 1 {
+		t.Error(i)
+	}
+	if i := b.Rank("c"); i != 4 {
+		t.Error(i)
+	}
+
+	if a := b.Dec("z", 1); a != b {
+		t.Error()
+	}
+	b = b.Dec("b", 2).Dec("a", 5)
+	checkWeights(t, b.tree)
+	if m := maps.Collect(b.All()); !maps.Equal(m, map[string]int{"b": 1, "c": 3}) {
+		t.Error(m)
+	}
+
+	for range b.All() {
+		break
+	}
+
+	defer func() { _ = recover() }()
+	b.Inc("a", -1)
+	t.Error()
+}
+
+func TestBag_algebra(t *testing.T) {
+	var b1, b2 Bag[int]
+	b1 = b1.Inc(1, 1).Inc(2, 2).Inc(3, 3)
+	b2 = b2.Inc(2, 5).Inc(3, 1).Inc(4, 1)
+
+	sum := b1.Sum(b2)
+	checkWeights(t, sum.tree)
+	if m := maps.Collect(sum.All()); !maps.Equal(m, map[int]int{1: 1, 2: 7, 3: 4, 4: 1}) || sum.Total() != 13 {
+		t.Error(m)
+	}
+
+	union := b1.Union(b2)
+	checkWeights(t, union.tree)
+	if m := maps.Collect(union.All()); !maps.Equal(m, map[int]int{1: 1, 2: 5, 3: 3, 4: 1}) || union.Total() != 10 {
+		t.Error(m)
+	}
+
+	inter := b1.Intersection(b2)
+	checkWeights(t, inter.tree)
+	if m := maps.Collect(inter.All()); !maps.Equal(m, map[int]int{2: 2, 3: 1}) || inter.Total() != 3 {
+		t.Error(m)
+	}
+}
+
+func TestWeightedQuantile(t *testing.T) {
+	var b Bag[float64]
+	if q := WeightedQuantile(b, 0.5, Linear); !math.IsNaN(q) {
+		t.Error(q)
+	}
+
+	// 1 2 2 2 10
+	b = b.Inc(1, 1).Inc(2, 3).Inc(10, 1).Inc(math.NaN(), 4)
+	if q := WeightedQuantile(b, 0.5, Linear); q != 2 {
+		t.Error(q)
+	}
+	if q := WeightedQuantile(b, 0.99, NearestRank); q != 10 {
+		t.Error(q)
+	}
+	if q := WeightedQuantile(b, 0.2, NearestRank); q != 1 {
+		t.Error(q)
+	}
+	if q := WeightedQuantile(b, 0.875, Linear); q != 6 {
+		t.Error(q)
+	}
+
+	// Ranks that are whole numbers are exact.
+	b = Bag[float64]{}
+	for i := 1; i <= 50; i++ {
+		b = b.Inc(float64(i), 2)
+	}
+	for _, p := range []float64{7, 14, 28} {
+		if q := WeightedQuantile(b, p/100, NearestRank); q != math.Ceil(p/2) {
+			t.Error(p, q)
+		}
+	}
+	b = Bag[float64]{}
+	for i := 1; i <= 101; i++ {
+		b = b.Inc(float64(i), 1)
+	}
+	for _, p := range []float64{7, 14, 28} {
+		if q := WeightedQuantile(b, p/100, Linear); q != p+1 {
+			t.Error(p, q)
+		}
+	}
+}
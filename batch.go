@@ -0,0 +1,92 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// PatchMany finds each of keys in this tree, calls update with the key
+// and the node for that key (or nil, if key is not found),
+// and returns a (possibly) modified tree.
+//
+// The update callback can opt to set/update the value for the key,
+// by returning (value, true), or not, by returning false.
+// It is called once per distinct key, in ascending key order.
+//
+// Each node is copied at most once, so for m keys
+// the cost is O(m·log(n/m + 1)), rather than O(m·log(n)).
+func (tree *Tree[K, V]) PatchMany(keys iter.Seq[K], update func(key K, node *Tree[K, V]) (value V, ok bool)) *Tree[K, V] {
+	return tree.patchMany(sortedKeys(keys), update)
+}
+
+func (tree *Tree[K, V]) patchMany(keys []K, update func(key K, node *Tree[K, V]) (value V, ok bool)) *Tree[K, V] {
+	if len(keys) == 0 {
+		return tree
+	}
+
+	if tree == nil {
+		// AA trees lean right, so round down.
+		mid := (len(keys) - 1) / 2
+		left := tree.patchMany(keys[:mid], update)
+		value, ok := update(keys[mid], nil)
+		right := tree.patchMany(keys[mid+1:], update)
+		if ok {
+			return join(left, &Tree[K, V]{key: keys[mid], value: value}, right)
+		}
+		return join2(left, right)
+	}
+
+	i, found := slices.BinarySearch(keys, tree.key)
+	left := tree.left.patchMany(keys[:i], update)
+	node := tree
+	if found {
+		if value, ok := update(tree.key, tree); ok {
+			copy := *tree
+			copy.value = value
+			node = &copy
+		}
+		i++
+	}
+	right := tree.right.patchMany(keys[i:], update)
+
+	if left == tree.left && right == tree.right {
+		return node
+	}
+	return join(left, node, right)
+}
+
+// DeleteMany returns a (possibly) modified tree with keys removed from it.
+//
+// Each node is copied at most once, so for m keys
+// the cost is O(m·log(n/m + 1)), rather than O(m·log(n)).
+func (tree *Tree[K, V]) DeleteMany(keys iter.Seq[K]) *Tree[K, V] {
+	return tree.deleteMany(sortedKeys(keys))
+}
+
+func (tree *Tree[K, V]) deleteMany(keys []K) *Tree[K, V] {
+	if tree == nil || len(keys) == 0 {
+		return tree
+	}
+
+	i, found := slices.BinarySearch(keys, tree.key)
+	left := tree.left.deleteMany(keys[:i])
+	if found {
+		i++
+	}
+	right := tree.right.deleteMany(keys[i:])
+
+	if found {
+		return join2(left, right)
+	}
+	return join(left, tree, right)
+}
+
+func sortedKeys[K cmp.Ordered](seq iter.Seq[K]) []K {
+	keys := slices.Collect(seq)
+	if !increasing(keys) {
+		slices.Sort(keys)
+		keys = slices.Compact(keys)
+	}
+	return keys
+}
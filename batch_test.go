@@ -0,0 +1,89 @@
+package aa
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTree_PatchMany(t *testing.T) {
+	var tt *Tree[int, int]
+	tt = tt.PatchMany(slices.Values([]int{5, 3, 1, 3}), func(key int, node *Tree[int, int]) (int, bool) {
+		return key * 10, node == nil
+	})
+	tt.check()
+	if !maps.Equal(tt.Collect(), map[int]int{1: 10, 3: 30, 5: 50}) {
+		t.Error(tt.Collect())
+	}
+
+	var keys []int
+	tt = tt.PatchMany(slices.Values([]int{0, 2, 3, 4, 6}), func(key int, node *Tree[int, int]) (int, bool) {
+		keys = append(keys, key)
+		if node != nil {
+			return node.Value() + 1, true
+		}
+		return 0, key%2 == 0
+	})
+	tt.check()
+	if !slices.Equal(keys, []int{0, 2, 3, 4, 6}) {
+		t.Error(keys)
+	}
+	if !maps.Equal(tt.Collect(), map[int]int{0: 0, 1: 10, 2: 0, 3: 31, 4: 0, 5: 50, 6: 0}) {
+		t.Error(tt.Collect())
+	}
+
+	if a := tt.PatchMany(slices.Values([]int{1, 7}), func(int, *Tree[int, int]) (int, bool) {
+		return 0, false
+	}); a != tt {
+		t.Errorf("%p ≠ %p", a, tt)
+	}
+}
+
+func TestTree_DeleteMany(t *testing.T) {
+	tt := MakeSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	if a := tt.DeleteMany(slices.Values([]int{-1, 10})); a != tt {
+		t.Errorf("%p ≠ %p", a, tt)
+	}
+
+	tt = tt.DeleteMany(slices.Values([]int{9, 0, 4, 5, 5}))
+	tt.check()
+	if !slices.Equal(slices.Collect(Set[int]{tt}.All()), []int{1, 2, 3, 6, 7, 8}) {
+		t.Error(tt.Collect())
+	}
+}
+
+func TestTree_PatchMany_random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	var tt *Tree[int, int]
+	for range 100 {
+		keys := make([]int, r.Intn(50))
+		for i := range keys {
+			keys[i] = r.Intn(1000)
+		}
+
+		want := tt
+		for _, k := range keys {
+			want = want.Put(k, k)
+		}
+		tt = tt.PatchMany(slices.Values(keys), func(key int, _ *Tree[int, int]) (int, bool) {
+			return key, true
+		})
+		tt.check()
+		if !Equal(tt, want) {
+			t.Fatal()
+		}
+
+		keys = keys[:r.Intn(len(keys)+1)]
+		for _, k := range keys {
+			want = want.Delete(k)
+		}
+		tt = tt.DeleteMany(slices.Values(keys))
+		tt.check()
+		if !Equal(tt, want) {
+			t.Fatal()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// BiMap is an immutable one-to-one map:
+// each key maps to a unique value, and each value to a unique key.
+// Both keys and values are ordered.
+//
+// The zero value for BiMap is the empty bimap.
+type BiMap[K, V cmp.Ordered] struct {
+	byKey   *Tree[K, V]
+	byValue *Tree[V, K]
+}
+
+// Len returns the number of pairs in this bimap.
+func (m BiMap[K, V]) Len() int {
+	return m.byKey.Len()
+}
+
+// Get retrieves the value for a given key;
+// found indicates whether key exists in this bimap.
+func (m BiMap[K, V]) Get(key K) (value V, found bool) {
+	return m.byKey.Get(key)
+}
+
+// GetKey retrieves the key for a given value;
+// found indicates whether value exists in this bimap.
+func (m BiMap[K, V]) GetKey(value V) (key K, found bool) {
+	return m.byValue.Get(value)
+}
+
+// Put returns a modified bimap with key mapped to value.
+//
+// Any existing pairs for key, or for value, are removed.
+func (m BiMap[K, V]) Put(key K, value V) BiMap[K, V] {
+	if old, found := m.byKey.Get(key); found && old == value {
+		return m
+	}
+	byKey, old, found := m.byKey.Swap(key, value)
+	byValue := m.byValue
+	if found {
+		byValue = byValue.Delete(old)
+	}
+	byValue, other, found := byValue.Swap(value, key)
+	if found && other != key {
+		byKey = byKey.Delete(other)
+	}
+	return BiMap[K, V]{byKey, byValue}
+}
+
+// Delete returns a (possibly) modified bimap with key removed from it.
+func (m BiMap[K, V]) Delete(key K) BiMap[K, V] {
+	byKey, value, found := m.byKey.LoadAndDelete(key)
+	if !found {
+		return m
+	}
+	return BiMap[K, V]{byKey, m.byValue.Delete(value)}
+}
+
+// DeleteValue returns a (possibly) modified bimap with value removed from it.
+func (m BiMap[K, V]) DeleteValue(value V) BiMap[K, V] {
+	byValue, key, found := m.byValue.LoadAndDelete(value)
+	if !found {
+		return m
+	}
+	return BiMap[K, V]{m.byKey.Delete(key), byValue}
+}
+
+// Keys returns the tree of pairs ordered by key.
+func (m BiMap[K, V]) Keys() *Tree[K, V] {
+	return m.byKey
+}
+
+// Values returns the tree of pairs ordered by value, mapped to their keys.
+func (m BiMap[K, V]) Values() *Tree[V, K] {
+	return m.byValue
+}
+
+// ByKey returns an iterator for the pairs in this bimap, in ascending key order.
+func (m BiMap[K, V]) ByKey() iter.Seq2[K, V] {
+	return m.byKey.Ascend()
+}
+
+// ByValue returns an iterator for the pairs in this bimap, in ascending value order.
+func (m BiMap[K, V]) ByValue() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for v, k := range m.byValue.Ascend() {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// RankByValue finds the rank of the value for key,
+// the number of values in this bimap less than it;
+// found indicates whether key exists in this bimap.
+func (m BiMap[K, V]) RankByValue(key K) (rank int, found bool) {
+	value, found := m.byKey.Get(key)
+	if !found {
+		return 0, false
+	}
+	return m.byValue.Rank(value), true
+}
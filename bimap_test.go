@@ -0,0 +1,70 @@
+package aa
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestBiMap(t *testing.T) {
+	var m BiMap[string, int]
+	m = m.Put("alice", 30).Put("bob", 10).Put("carol", 20)
+
+	if m.Len() != 3 {
+		t.Error(m.Len())
+	}
+	if v, ok := m.Get("bob"); !ok || v != 10 {
+		t.Error(v, ok)
+	}
+	if k, ok := m.GetKey(20); !ok || k != "carol" {
+		t.Error(k, ok)
+	}
+
+	var keys []string
+	for k := range m.ByValue() {
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, []string{"bob", "carol", "alice"}) {
+		t.Error(keys)
+	}
+	if m := maps.Collect(m.ByKey()); len(m) != 3 {
+		t.Error(m)
+	}
+	if r, ok := m.RankByValue("alice"); !ok || r != 2 {
+		t.Error(r, ok)
+	}
+	if _, ok := m.RankByValue("dave"); ok {
+		t.Error()
+	}
+
+	// Same pair.
+	if a := m.Put("bob", 10); a != m {
+		t.Error()
+	}
+
+	// New value for key.
+	m = m.Put("bob", 40)
+	if _, ok := m.GetKey(10); ok || m.Len() != 3 {
+		t.Error(m.Len())
+	}
+
+	// Value taken by another key.
+	m = m.Put("dave", 40)
+	if _, ok := m.Get("bob"); ok || m.Len() != 3 {
+		t.Error(m.Len())
+	}
+	if k, _ := m.GetKey(40); k != "dave" {
+		t.Error(k)
+	}
+
+	m = m.Delete("dave").Delete("zed").DeleteValue(20).DeleteValue(99)
+	if m.Len() != 1 || m.Values().Len() != 1 || m.Keys().Len() != 1 {
+		t.Error(m.Len())
+	}
+	m.Keys().check()
+	m.Values().check()
+
+	for range m.ByValue() {
+		break
+	}
+}
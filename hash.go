@@ -0,0 +1,128 @@
+package aa
+
+import "cmp"
+
+// Hasher computes content hashes of trees.
+//
+// The hash of a tree depends only on its key/value pairs, not on its shape:
+// it is the (wrapping) sum of the hashes of every key/value pair.
+// Trees with equal contents have equal hashes, regardless of their history,
+// and the hash of any key range can be computed in logarithmic time,
+// so replicas can compare ranges and bisect the ones that differ.
+//
+// Hashes aren't stored in trees: Hasher computes them on demand,
+// and caches the hashes of the subtrees it visits.
+// Hashing a tree for the first time costs O(n) time,
+// and caches a hash for about half its nodes (leaves aren't cached).
+// Hashing it again, or hashing a later version of it, costs O(log n),
+// plus the number of nodes not shared with cached subtrees.
+//
+// Since trees are immutable, cached hashes never go stale,
+// but they do keep their subtrees reachable.
+// The cache is bounded: once full, the least recently used
+// half of it is released (approximately);
+// call Reset to release it all.
+//
+// A Hasher is not safe for concurrent use.
+type Hasher[K cmp.Ordered, V any] struct {
+	hash  func(key K, value V) uint64
+	cache map[*Tree[K, V]]uint64 // recently used
+	older map[*Tree[K, V]]uint64 // released when cache fills
+	limit int                    // per generation, or 0 if unlimited
+}
+
+// NewHasher creates a Hasher that hashes key/value pairs with hash,
+// and caches at most size subtree hashes
+// (or an unlimited number, if size ≤ 0).
+//
+// For hashes to be comparable across processes,
+// hash must be deterministic (not seeded per process).
+func NewHasher[K cmp.Ordered, V any](hash func(key K, value V) uint64, size int) *Hasher[K, V] {
+	h := &Hasher[K, V]{hash: hash}
+	if size > 0 {
+		h.limit = max(1, size/2)
+	}
+	return h
+}
+
+// Reset clears the cache of subtree hashes.
+func (h *Hasher[K, V]) Reset() {
+	h.cache = nil
+	h.older = nil
+}
+
+// Hash returns the content hash of tree.
+//
+// Note: the hash of the empty tree (nil) is 0.
+func (h *Hasher[K, V]) Hash(tree *Tree[K, V]) uint64 {
+	if tree == nil {
+		return 0
+	}
+	if tree.left == nil && tree.right == nil {
+		return h.node(tree)
+	}
+	if sum, ok := h.cache[tree]; ok {
+		return sum
+	}
+	sum, ok := h.older[tree]
+	if !ok {
+		sum = h.Hash(tree.left) + h.node(tree) + h.Hash(tree.right)
+	}
+	h.store(tree, sum)
+	return sum
+}
+
+func (h *Hasher[K, V]) store(tree *Tree[K, V], sum uint64) {
+	if h.cache == nil {
+		h.cache = make(map[*Tree[K, V]]uint64)
+	}
+	if h.limit > 0 && len(h.cache) >= h.limit {
+		h.older = h.cache
+		h.cache = make(map[*Tree[K, V]]uint64, h.limit)
+	}
+	h.cache[tree] = sum
+}
+
+// HashLess returns the content hash of the key/value pairs
+// in tree with keys less than key, much like Rank counts them.
+func (h *Hasher[K, V]) HashLess(tree *Tree[K, V], key K) uint64 {
+	var sum uint64
+	for tree != nil {
+		switch cmp.Compare(key, tree.key) {
+		case -1:
+			tree = tree.left
+
+		case +1:
+			sum += h.Hash(tree.left) + h.node(tree)
+			tree = tree.right
+
+		default:
+			return sum + h.Hash(tree.left)
+		}
+	}
+	return sum
+}
+
+// HashRange returns the content hash of the key/value pairs
+// in tree with keys greater-than or equal-to lo, and less than hi.
+//
+//	h.HashRange(tree, lo, hi) ⟹ h.HashLess(tree, hi) - h.HashLess(tree, lo), iff lo < hi
+func (h *Hasher[K, V]) HashRange(tree *Tree[K, V], lo, hi K) uint64 {
+	if !cmp.Less(lo, hi) {
+		return 0
+	}
+	return h.HashLess(tree, hi) - h.HashLess(tree, lo)
+}
+
+func (h *Hasher[K, V]) node(tree *Tree[K, V]) uint64 {
+	// Summing raw hashes would make
+	// related pairs cancel out; mix them first.
+	// https://github.com/aappleby/smhasher/wiki/MurmurHash3
+	x := h.hash(tree.key, tree.value)
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,82 @@
+package aa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHasher(t *testing.T) {
+	h := NewHasher(func(key, value int) uint64 {
+		return uint64(key)<<32 | uint64(value)
+	}, 0)
+
+	if h.Hash(nil) != 0 {
+		t.Error()
+	}
+
+	// Same contents, different histories.
+	var t1, t2 *Tree[int, int]
+	r := rand.New(rand.NewSource(42))
+	for _, i := range r.Perm(100) {
+		t1 = t1.Put(i, -i)
+	}
+	for i := range 200 {
+		t2 = t2.Put(i, -i)
+	}
+	for i := 100; i < 200; i++ {
+		t2 = t2.Delete(i)
+	}
+
+	if h.Hash(t1) != h.Hash(t2) {
+		t.Error()
+	}
+	if h.Hash(t1) == h.Hash(t1.Put(50, 0)) {
+		t.Error()
+	}
+	if h.Hash(t1) == h.Hash(t1.Delete(50)) {
+		t.Error()
+	}
+
+	for range 100 {
+		lo, hi := r.Intn(120)-10, r.Intn(120)-10
+		if h.HashRange(t1, lo, hi) != h.HashRange(t2, lo, hi) {
+			t.Fatal(lo, hi)
+		}
+		var want *Tree[int, int]
+		for i := max(lo, 0); i < min(hi, 100); i++ {
+			want = want.Put(i, -i)
+		}
+		if h.HashRange(t1, lo, hi) != h.Hash(want) {
+			t.Fatal(lo, hi)
+		}
+	}
+
+	h.Reset()
+	if h.HashLess(t1, 100) != h.Hash(t2) {
+		t.Error()
+	}
+}
+
+func TestHasher_bounded(t *testing.T) {
+	hash := func(key, value int) uint64 {
+		return uint64(key)<<32 | uint64(value)
+	}
+	h1 := NewHasher(hash, 0)
+	h2 := NewHasher(hash, 16)
+
+	var tt *Tree[int, int]
+	r := rand.New(rand.NewSource(42))
+	for i := range 1000 {
+		tt = tt.Put(r.Intn(500), i)
+		lo, hi := r.Intn(500), r.Intn(500)
+		if h1.Hash(tt) != h2.Hash(tt) || h1.HashRange(tt, lo, hi) != h2.HashRange(tt, lo, hi) {
+			t.Fatal(i)
+		}
+		if n := len(h2.cache) + len(h2.older); n > 16 {
+			t.Fatal(n)
+		}
+	}
+	if len(h1.cache) <= 16 {
+		t.Error(len(h1.cache))
+	}
+}
@@ -0,0 +1,218 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Interval is the half-open interval [Lo, Hi).
+type Interval[K cmp.Ordered] struct {
+	Lo, Hi K
+}
+
+// IntervalSet is an immutable set of disjoint half-open intervals.
+//
+// The zero value for IntervalSet is the empty set.
+//
+// Intervals are keyed by their start, and coalesced on insertion,
+// so no two intervals in the set overlap or touch.
+type IntervalSet[K cmp.Ordered] struct {
+	tree *Tree[K, span[K, struct{}]]
+}
+
+// span is the end and value of an interval keyed by its start.
+type span[K cmp.Ordered, V any] struct {
+	hi    K
+	value V
+}
+
+// Len returns the number of intervals in this set.
+func (s IntervalSet[K]) Len() int {
+	return s.tree.Len()
+}
+
+// All returns an ascending iterator for the intervals in this set.
+func (s IntervalSet[K]) All() iter.Seq[Interval[K]] {
+	return func(yield func(Interval[K]) bool) {
+		for lo, sp := range s.tree.Ascend() {
+			if !yield(Interval[K]{lo, sp.hi}) {
+				return
+			}
+		}
+	}
+}
+
+// Contains reports whether x is in this set.
+func (s IntervalSet[K]) Contains(x K) bool {
+	return spanAt(s.tree, x) != nil
+}
+
+// Covers reports whether every point of [lo, hi) is in this set.
+// An empty interval is always covered.
+func (s IntervalSet[K]) Covers(lo, hi K) bool {
+	if !cmp.Less(lo, hi) {
+		return true
+	}
+	node := spanAt(s.tree, lo)
+	return node != nil && !cmp.Less(node.value.hi, hi)
+}
+
+// Insert returns a (possibly) modified set with [lo, hi) added to it,
+// coalesced with any intervals it overlaps or touches.
+func (s IntervalSet[K]) Insert(lo, hi K) IntervalSet[K] {
+	if !cmp.Less(lo, hi) || s.Covers(lo, hi) {
+		return s
+	}
+	if node := s.tree.Floor(lo); node != nil && !cmp.Less(node.value.hi, lo) {
+		lo = node.key
+	}
+	if node := s.tree.Floor(hi); node != nil && cmp.Less(hi, node.value.hi) {
+		hi = node.value.hi
+	}
+	left, right := cut(s.tree, lo, hi)
+	node := &Tree[K, span[K, struct{}]]{key: lo, value: span[K, struct{}]{hi: hi}}
+	return IntervalSet[K]{join(left, node, right)}
+}
+
+// Remove returns a (possibly) modified set with [lo, hi) removed from it,
+// splitting any interval that straddles it.
+func (s IntervalSet[K]) Remove(lo, hi K) IntervalSet[K] {
+	if !cmp.Less(lo, hi) {
+		return s
+	}
+	left, right := cut(s.tree, lo, hi)
+	return IntervalSet[K]{join2(left, right)}
+}
+
+// Gaps returns an ascending iterator for the intervals of [lo, hi)
+// not in this set.
+func (s IntervalSet[K]) Gaps(lo, hi K) iter.Seq[Interval[K]] {
+	return func(yield func(Interval[K]) bool) {
+		for k, sp := range between(s.tree, lo, hi) {
+			if cmp.Less(lo, k) && !yield(Interval[K]{lo, k}) {
+				return
+			}
+			lo = sp.hi
+		}
+		if cmp.Less(lo, hi) {
+			yield(Interval[K]{lo, hi})
+		}
+	}
+}
+
+// Union returns the set union of s and t.
+func (s IntervalSet[K]) Union(t IntervalSet[K]) IntervalSet[K] {
+	if s.Len() < t.Len() {
+		s, t = t, s
+	}
+	for i := range t.All() {
+		s = s.Insert(i.Lo, i.Hi)
+	}
+	return s
+}
+
+// Intersection returns the set intersection of s and t.
+func (s IntervalSet[K]) Intersection(t IntervalSet[K]) IntervalSet[K] {
+	if s.Len() < t.Len() {
+		s, t = t, s
+	}
+	var res *Tree[K, span[K, struct{}]]
+	for i := range t.All() {
+		res = join2(res, clip(s.tree, i.Lo, i.Hi))
+	}
+	return IntervalSet[K]{res}
+}
+
+// Difference returns the set difference of s and t.
+func (s IntervalSet[K]) Difference(t IntervalSet[K]) IntervalSet[K] {
+	for i := range t.All() {
+		s = s.Remove(i.Lo, i.Hi)
+	}
+	return s
+}
+
+// Equal reports whether s and t contain the same intervals.
+func (s IntervalSet[K]) Equal(t IntervalSet[K]) bool {
+	if s.Len() != t.Len() {
+		return false
+	}
+	next, stop := iter.Pull(t.All())
+	defer stop()
+	for i := range s.All() {
+		if j, _ := next(); i != j {
+			return false
+		}
+	}
+	return true
+}
+
+// spanAt returns the node for the interval that contains x,
+// or nil if no such interval exists.
+func spanAt[K cmp.Ordered, V any](tree *Tree[K, span[K, V]], x K) *Tree[K, span[K, V]] {
+	if node := tree.Floor(x); node != nil && cmp.Less(x, node.value.hi) {
+		return node
+	}
+	return nil
+}
+
+// between returns an ascending iterator
+// for the intervals in tree that overlap [lo, hi).
+func between[K cmp.Ordered, V any](tree *Tree[K, span[K, V]], lo, hi K) iter.Seq2[K, span[K, V]] {
+	return func(yield func(K, span[K, V]) bool) {
+		if !cmp.Less(lo, hi) {
+			return
+		}
+		if node := spanAt(tree, lo); node != nil {
+			if !yield(node.key, node.value) {
+				return
+			}
+		}
+		for k, sp := range tree.AscendCeil(lo) {
+			if k == lo {
+				continue // spanAt already found it.
+			}
+			if !cmp.Less(k, hi) || !yield(k, sp) {
+				return
+			}
+		}
+	}
+}
+
+// cut removes [lo, hi) from tree, truncating intervals that straddle it,
+// and returns a left tree with the intervals before lo,
+// and a right tree with the intervals after hi.
+func cut[K cmp.Ordered, V any](tree *Tree[K, span[K, V]], lo, hi K) (left, right *Tree[K, span[K, V]]) {
+	left, right = splitLess(tree, lo)
+	mid, right := splitLess(right, hi)
+
+	last := mid.Max()
+	if last == nil {
+		last = left.Max()
+	}
+	if last != nil && cmp.Less(hi, last.value.hi) {
+		right = right.Put(hi, last.value)
+	}
+	if last := left.Max(); last != nil && cmp.Less(lo, last.value.hi) {
+		left = left.Put(last.key, span[K, V]{hi: lo, value: last.value.value})
+	}
+	return left, right
+}
+
+// clip returns a tree with the intervals in tree truncated to [lo, hi).
+func clip[K cmp.Ordered, V any](tree *Tree[K, span[K, V]], lo, hi K) *Tree[K, span[K, V]] {
+	if !cmp.Less(lo, hi) {
+		return nil
+	}
+	left, mid := splitLess(tree, lo)
+	mid, _ = splitLess(mid, hi)
+
+	if last := mid.Max(); last != nil && cmp.Less(hi, last.value.hi) {
+		mid = mid.Put(last.key, span[K, V]{hi: hi, value: last.value.value})
+	}
+	if last := left.Max(); last != nil && cmp.Less(lo, last.value.hi) {
+		hi := min(hi, last.value.hi)
+		node := &Tree[K, span[K, V]]{key: lo, value: span[K, V]{hi: hi, value: last.value.value}}
+		mid = join(nil, node, mid)
+	}
+	return mid
+}
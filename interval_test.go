@@ -0,0 +1,126 @@
+package aa
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// intervalMask returns the points of s in [0, 64) as a bitmask,
+// and checks that its intervals are disjoint and coalesced.
+func intervalMask(t *testing.T, s IntervalSet[int]) (mask uint64) {
+	t.Helper()
+	s.tree.check()
+	last := -1
+	for i := range s.All() {
+		if i.Lo <= last || i.Lo >= i.Hi {
+			t.Fatalf("bad interval %v after %d", i, last)
+		}
+		for x := i.Lo; x < i.Hi; x++ {
+			mask |= 1 << x
+		}
+		last = i.Hi
+	}
+	return mask
+}
+
+func rangeMask(lo, hi int) (mask uint64) {
+	for x := lo; x < hi; x++ {
+		mask |= 1 << x
+	}
+	return mask
+}
+
+func TestIntervalSet(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	random := func() (IntervalSet[int], uint64) {
+		var s IntervalSet[int]
+		var mask uint64
+		for range r.Intn(20) {
+			lo, hi := r.Intn(65), r.Intn(65)
+			if r.Intn(3) == 0 {
+				s = s.Remove(lo, hi)
+				mask &^= rangeMask(lo, hi)
+			} else {
+				s = s.Insert(lo, hi)
+				mask |= rangeMask(lo, hi)
+			}
+			if got := intervalMask(t, s); got != mask {
+				t.Fatalf("%x ≠ %x", got, mask)
+			}
+		}
+		return s, mask
+	}
+
+	for range 200 {
+		s, ms := random()
+		u, mu := random()
+
+		for x := range 64 {
+			if s.Contains(x) != (ms&(1<<x) != 0) {
+				t.Fatal(x)
+			}
+		}
+		lo, hi := r.Intn(65), r.Intn(65)
+		want := rangeMask(lo, hi)
+		if s.Covers(lo, hi) != (ms&want == want) {
+			t.Fatal(lo, hi)
+		}
+		var gaps uint64
+		for g := range s.Gaps(lo, hi) {
+			if g.Lo >= g.Hi || gaps&rangeMask(g.Lo, g.Hi) != 0 {
+				t.Fatal(g)
+			}
+			gaps |= rangeMask(g.Lo, g.Hi)
+		}
+		if gaps != want&^ms {
+			t.Fatalf("gaps %x ≠ %x", gaps, want&^ms)
+		}
+
+		if got := intervalMask(t, s.Union(u)); got != ms|mu {
+			t.Fatal("union")
+		}
+		if got := intervalMask(t, s.Intersection(u)); got != ms&mu {
+			t.Fatal("intersection")
+		}
+		if got := intervalMask(t, s.Difference(u)); got != ms&^mu {
+			t.Fatal("difference")
+		}
+		if s.Equal(u) != (ms == mu) {
+			t.Fatal("equal")
+		}
+	}
+}
+
+func TestIntervalSet_coalesce(t *testing.T) {
+	var s IntervalSet[int]
+	s = s.Insert(0, 10).Insert(20, 30).Insert(10, 20)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []Interval[int]{{0, 30}}) {
+		t.Error(got)
+	}
+	if !s.Covers(5, 25) || s.Covers(25, 35) || !s.Covers(40, 40) {
+		t.Error()
+	}
+	if u := s.Insert(5, 25); u.tree != s.tree {
+		t.Error("copied")
+	}
+
+	s = s.Remove(10, 20)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []Interval[int]{{0, 10}, {20, 30}}) {
+		t.Error(got)
+	}
+	if s.Contains(10) || !s.Contains(9) || !s.Contains(20) || s.Contains(30) {
+		t.Error()
+	}
+	if got := slices.Collect(s.Gaps(-5, 35)); !slices.Equal(got, []Interval[int]{{-5, 0}, {10, 20}, {30, 35}}) {
+		t.Error(got)
+	}
+
+	for range s.All() {
+		break
+	}
+	for range s.Gaps(-5, 35) {
+		break
+	}
+}
@@ -55,6 +55,50 @@ func (tree *Tree[K, V]) Partition(pred func(node *Tree[K, V]) bool) (t, f *Tree[
 	return join2(lt, rt), join(lf, tree, rf)
 }
 
+// SplitFunc partitions this tree around the first node for which pred returns true.
+// It returns a left tree of nodes for which pred returns false,
+// and a right tree of nodes for which it returns true.
+//
+// The predicate must be monotone: false for a (possibly empty) prefix
+// of the keys in this tree, and true for the remaining ones.
+// Only O(log n) nodes are tested.
+func (tree *Tree[K, V]) SplitFunc(pred func(node *Tree[K, V]) bool) (left, right *Tree[K, V]) {
+	if tree == nil {
+		return nil, nil
+	}
+	if pred(tree) {
+		left, right = tree.left.SplitFunc(pred)
+		return left, join(right, tree, tree.right)
+	}
+	left, right = tree.right.SplitFunc(pred)
+	return join(tree.left, tree, left), right
+}
+
+// Join returns a tree with the keys in left, the key in node,
+// and the keys in right (the subtrees of node are ignored).
+//
+// Note: Join panics unless every key in left is less than the key in node,
+// and every key in right is greater than it.
+func Join[K cmp.Ordered, V any](left, node, right *Tree[K, V]) *Tree[K, V] {
+	if max := left.Max(); max != nil && !cmp.Less(max.key, node.key) {
+		panic("left keys must be less than node key")
+	}
+	if min := right.Min(); min != nil && !cmp.Less(node.key, min.key) {
+		panic("right keys must be greater than node key")
+	}
+	return join(left, node, right)
+}
+
+// Concat returns a tree with the keys in left and right.
+//
+// Note: Concat panics unless every key in left is less than every key in right.
+func Concat[K cmp.Ordered, V any](left, right *Tree[K, V]) *Tree[K, V] {
+	if max, min := left.Max(), right.Min(); max != nil && min != nil && !cmp.Less(max.key, min.key) {
+		panic("left keys must be less than right keys")
+	}
+	return join2(left, right)
+}
+
 func join[K cmp.Ordered, V any](left, node, right *Tree[K, V]) *Tree[K, V] {
 	if left == node.left && right == node.right {
 		return node
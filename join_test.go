@@ -124,3 +124,67 @@ func join2Fuzzer(t *testing.T, ints []byte) {
 	tree := join2(left, right)
 	tree.check()
 }
+
+func TestTree_SplitFunc(t *testing.T) {
+	tt := MakeMap(map[int]int{0: 0, 1: 10, 2: 20, 3: 30, 4: 40, 5: 50})
+
+	for i := range 7 {
+		left, right := tt.SplitFunc(func(node *Tree[int, int]) bool {
+			return node.Value() >= 10*i
+		})
+		left.check()
+		right.check()
+		if left.Len() != i || right.Len() != 6-i {
+			t.Errorf("%d: %d, %d", i, left.Len(), right.Len())
+		}
+		if i > 0 && left.Max().Key() != i-1 {
+			t.Error(left.Max().Key())
+		}
+		if i < 6 && right.Min().Key() != i {
+			t.Error(right.Min().Key())
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	left := MakeSet(0, 1, 2)
+	right := MakeSet(4, 5, 6, 7, 8, 9)
+	node := MakeSet(3)
+
+	tt := Join(left, node, right)
+	tt.check()
+	if tt.Len() != 10 {
+		t.Error(tt.Len())
+	}
+	if a := Join[int, struct{}](nil, node, nil); a.Len() != 1 {
+		t.Error(a.Len())
+	}
+
+	defer func() { _ = recover() }()
+	Join(right, node, left)
+	t.Error()
+}
+
+func TestJoin_right(t *testing.T) {
+	defer func() { _ = recover() }()
+	Join(nil, MakeSet(5), MakeSet(1))
+	t.Error()
+}
+
+func TestConcat(t *testing.T) {
+	left := MakeSet(0, 1, 2)
+	right := MakeSet(3, 4, 5, 6, 7, 8, 9)
+
+	tt := Concat(left, right)
+	tt.check()
+	if tt.Len() != 10 {
+		t.Error(tt.Len())
+	}
+	if a := Concat(left, nil); a != left {
+		t.Errorf("%p ≠ %p", a, left)
+	}
+
+	defer func() { _ = recover() }()
+	Concat(right, left)
+	t.Error()
+}
@@ -0,0 +1,99 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Joined holds the values for a key in two trees,
+// and whether each tree has that key.
+type Joined[V1, V2 any] struct {
+	Left    V1
+	Right   V2
+	InLeft  bool
+	InRight bool
+}
+
+// JoinSeq returns an ascending iterator for the full outer join of two trees:
+// every key in either tree, with the values for it in both trees.
+//
+// Subtrees of either tree that fall between keys of the other
+// are iterated wholesale.
+func JoinSeq[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2]) iter.Seq2[K, Joined[V1, V2]] {
+	return func(yield func(K, Joined[V1, V2]) bool) { outerJoin(t1, t2, nil, nil, yield) }
+}
+
+func outerJoin[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2], lo, hi *K, yield func(K, Joined[V1, V2]) bool) bool {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == nil:
+		return t2.ascend(func(k K, v V2) bool {
+			return yield(k, Joined[V1, V2]{Right: v, InRight: true})
+		})
+	case t2 == nil:
+		return t1.ascendBetween(lo, hi, func(k K, v V1) bool {
+			return yield(k, Joined[V1, V2]{Left: v, InLeft: true})
+		})
+	}
+	if !outerJoin(t1, t2.left, lo, &t2.key, yield) {
+		return false
+	}
+	j := Joined[V1, V2]{Right: t2.value, InRight: true}
+	if node := t1.lookup(t2.key); node != nil {
+		j.Left, j.InLeft = node.value, true
+	}
+	return yield(t2.key, j) && outerJoin(t1, t2.right, &t2.key, hi, yield)
+}
+
+// LeftJoin returns an ascending iterator for the left outer join of two trees:
+// every key in t1, with the values for it in both trees.
+//
+// Subtrees of t1 that fall between keys of t2 are iterated wholesale.
+func LeftJoin[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2]) iter.Seq2[K, Joined[V1, V2]] {
+	return func(yield func(K, Joined[V1, V2]) bool) { leftJoin(t1, t2, nil, nil, yield) }
+}
+
+func leftJoin[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2], lo, hi *K, yield func(K, Joined[V1, V2]) bool) bool {
+	t2 = t2.narrow(lo, hi)
+	switch {
+	case t1 == nil:
+		return true
+	case t2 == nil:
+		return t1.ascend(func(k K, v V1) bool {
+			return yield(k, Joined[V1, V2]{Left: v, InLeft: true})
+		})
+	}
+	if !leftJoin(t1.left, t2, lo, &t1.key, yield) {
+		return false
+	}
+	j := Joined[V1, V2]{Left: t1.value, InLeft: true}
+	if node := t2.lookup(t1.key); node != nil {
+		j.Right, j.InRight = node.value, true
+	}
+	return yield(t1.key, j) && leftJoin(t1.right, t2, &t1.key, hi, yield)
+}
+
+// InnerJoin returns an ascending iterator for the inner join of two trees:
+// every key in both trees, with the values for it in both trees.
+//
+// Subtrees of t1 that fall between keys of t2 are skipped.
+func InnerJoin[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2]) iter.Seq2[K, Joined[V1, V2]] {
+	return func(yield func(K, Joined[V1, V2]) bool) { innerJoin(t1, t2, nil, nil, yield) }
+}
+
+func innerJoin[K cmp.Ordered, V1, V2 any](t1 *Tree[K, V1], t2 *Tree[K, V2], lo, hi *K, yield func(K, Joined[V1, V2]) bool) bool {
+	t2 = t2.narrow(lo, hi)
+	if t1 == nil || t2 == nil {
+		return true
+	}
+	if !innerJoin(t1.left, t2, lo, &t1.key, yield) {
+		return false
+	}
+	if node := t2.lookup(t1.key); node != nil {
+		j := Joined[V1, V2]{Left: t1.value, Right: node.value, InLeft: true, InRight: true}
+		if !yield(t1.key, j) {
+			return false
+		}
+	}
+	return innerJoin(t1.right, t2, &t1.key, hi, yield)
+}
@@ -0,0 +1,78 @@
+package aa
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestJoinSeq(t *testing.T) {
+	t1 := MakeMap(map[int]string{1: "1", 2: "2", 4: "4", 7: "7"})
+	t2 := MakeMap(map[int]int{0: 0, 2: 2, 3: 3, 4: 4, 8: 8})
+
+	type row struct {
+		key int
+		Joined[string, int]
+	}
+	collect := func(seq func(yield func(int, Joined[string, int]) bool)) (out []row) {
+		for k, j := range seq {
+			if j.InLeft && j.Left != strconv.Itoa(k) || j.InRight && j.Right != k {
+				t.Error(k, j)
+			}
+			out = append(out, row{k, j})
+		}
+		return out
+	}
+	keys := func(rows []row, inLeft, inRight bool) (out []int) {
+		for _, r := range rows {
+			if r.InLeft == inLeft && r.InRight == inRight {
+				out = append(out, r.key)
+			}
+		}
+		return out
+	}
+
+	full := collect(JoinSeq(t1, t2))
+	if len(full) != 7 {
+		t.Error(full)
+	}
+	if k := keys(full, true, true); !slices.Equal(k, []int{2, 4}) {
+		t.Error(k)
+	}
+	if k := keys(full, true, false); !slices.Equal(k, []int{1, 7}) {
+		t.Error(k)
+	}
+	if k := keys(full, false, true); !slices.Equal(k, []int{0, 3, 8}) {
+		t.Error(k)
+	}
+
+	left := collect(LeftJoin(t1, t2))
+	if len(left) != 4 {
+		t.Error(left)
+	}
+	if k := keys(left, true, true); !slices.Equal(k, []int{2, 4}) {
+		t.Error(k)
+	}
+	if k := keys(left, true, false); !slices.Equal(k, []int{1, 7}) {
+		t.Error(k)
+	}
+
+	inner := collect(InnerJoin(t1, t2))
+	if k := keys(inner, true, true); len(inner) != 2 || !slices.Equal(k, []int{2, 4}) {
+		t.Error(inner)
+	}
+
+	for _, seq := range []func(yield func(int, Joined[string, int]) bool){
+		JoinSeq(t1, t2), LeftJoin(t1, t2), InnerJoin(t1, t2),
+	} {
+		for i := range 8 {
+			n := 0
+			for range seq {
+				if n == i {
+					break
+				}
+				n++
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Leaderboard is an immutable ranking of IDs by score:
+// higher scores rank first, and ties are ranked by ascending ID.
+//
+// The zero value for Leaderboard is the empty leaderboard.
+//
+// All queries are O(log n), plus the number of entries returned.
+type Leaderboard[ID, Score cmp.Ordered] struct {
+	scores *Tree[ID, Score]
+	board  *Tree[Score, weighted[*Tree[ID, struct{}]]]
+}
+
+// Len returns the number of entries in this leaderboard.
+func (l Leaderboard[ID, Score]) Len() int {
+	return l.scores.Len()
+}
+
+// Score retrieves the score for id;
+// found indicates whether id exists in this leaderboard.
+func (l Leaderboard[ID, Score]) Score(id ID) (score Score, found bool) {
+	return l.scores.Get(id)
+}
+
+// Upsert returns a modified leaderboard with the score for id set to score.
+func (l Leaderboard[ID, Score]) Upsert(id ID, score Score) Leaderboard[ID, Score] {
+	if old, found := l.scores.Get(id); found {
+		if old == score {
+			return l
+		}
+		l = l.Remove(id)
+	}
+	return Leaderboard[ID, Score]{
+		scores: l.scores.Put(id, score),
+		board: reweigh(l.board.Patch(score, func(node *Tree[Score, weighted[*Tree[ID, struct{}]]]) (weighted[*Tree[ID, struct{}]], bool) {
+			var ids *Tree[ID, struct{}]
+			if node != nil {
+				ids = node.value.val
+			}
+			ids = ids.Add(id)
+			return weighted[*Tree[ID, struct{}]]{val: ids, weight: ids.Len()}, true
+		})),
+	}
+}
+
+// Remove returns a (possibly) modified leaderboard with id removed from it.
+func (l Leaderboard[ID, Score]) Remove(id ID) Leaderboard[ID, Score] {
+	scores, score, found := l.scores.LoadAndDelete(id)
+	if !found {
+		return l
+	}
+	board := l.board
+	if ids := board.lookup(score).value.val.Delete(id); ids == nil {
+		board = board.Delete(score)
+	} else {
+		board = board.Put(score, weighted[*Tree[ID, struct{}]]{val: ids, weight: ids.Len()})
+	}
+	return Leaderboard[ID, Score]{scores, reweigh(board)}
+}
+
+// Rank returns the rank of id, from 0 for the top entry;
+// found indicates whether id exists in this leaderboard.
+func (l Leaderboard[ID, Score]) Rank(id ID) (rank int, found bool) {
+	score, found := l.scores.Get(id)
+	if !found {
+		return 0, false
+	}
+	node := l.board.lookup(score)
+	above := totalWeight(l.board) - weightRank(l.board, score) - node.value.weight
+	return above + node.value.val.Rank(id), true
+}
+
+// PercentileRank returns the percentage of entries ranked below id,
+// counting id itself as half below (so the result is strictly between 0 and 100);
+// found indicates whether id exists in this leaderboard.
+func (l Leaderboard[ID, Score]) PercentileRank(id ID) (percentile float64, found bool) {
+	rank, found := l.Rank(id)
+	if !found {
+		return 0, false
+	}
+	n := l.Len()
+	return 100 * (float64(n-rank-1) + 0.5) / float64(n), true
+}
+
+// Select returns the entry with the given rank, from 0 for the top entry;
+// ok is false if rank is out of range.
+func (l Leaderboard[ID, Score]) Select(rank int) (id ID, score Score, ok bool) {
+	node, off := l.selectNode(rank)
+	if node == nil {
+		return
+	}
+	return node.value.val.Select(off).key, node.key, true
+}
+
+// selectNode finds the score with the given rank,
+// and the index of the ID with that rank among the IDs with that score.
+func (l Leaderboard[ID, Score]) selectNode(rank int) (*Tree[Score, weighted[*Tree[ID, struct{}]]], int) {
+	// Weights ascend by score; ranks descend.
+	node, off := weightSelect(l.board, totalWeight(l.board)-1-rank)
+	if node == nil || rank < 0 {
+		return nil, 0
+	}
+	return node, node.value.weight - 1 - off
+}
+
+// Page returns an iterator for at most limit entries,
+// starting at rank offset, in rank order.
+// A negative offset is treated as zero.
+func (l Leaderboard[ID, Score]) Page(offset, limit int) iter.Seq2[ID, Score] {
+	return func(yield func(ID, Score) bool) {
+		node, off := l.selectNode(max(0, offset))
+		if node == nil || limit <= 0 {
+			return
+		}
+		first := node.value.val.Select(off).key
+		for score, w := range l.board.DescendFloor(node.key) {
+			seq := w.val.Ascend()
+			if score == node.key {
+				seq = w.val.AscendCeil(first)
+			}
+			for id := range seq {
+				if !yield(id, score) {
+					return
+				}
+				if limit--; limit == 0 {
+					return
+				}
+			}
+		}
+	}
+}
+
+// TopN returns an iterator for the top n entries, in rank order.
+func (l Leaderboard[ID, Score]) TopN(n int) iter.Seq2[ID, Score] {
+	return l.Page(0, n)
+}
+
+// AroundMe returns an iterator for the entries ranked within k of id,
+// in rank order (or an empty iterator if id doesn't exist in this leaderboard).
+func (l Leaderboard[ID, Score]) AroundMe(id ID, k int) iter.Seq2[ID, Score] {
+	rank, found := l.Rank(id)
+	if !found || k < 0 {
+		return l.Page(0, 0)
+	}
+	k = min(k, l.Len())
+	start := max(0, rank-k)
+	return l.Page(start, rank+k+1-start)
+}
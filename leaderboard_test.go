@@ -0,0 +1,121 @@
+package aa
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestLeaderboard(t *testing.T) {
+	var l Leaderboard[string, int]
+	l = l.Upsert("alice", 50).Upsert("bob", 70).Upsert("carol", 50)
+	l = l.Upsert("dave", 90).Upsert("erin", 10).Upsert("bob", 50)
+	checkWeights(t, l.board)
+
+	if l.Len() != 5 {
+		t.Error(l.Len())
+	}
+	if s, ok := l.Score("bob"); !ok || s != 50 {
+		t.Error(s, ok)
+	}
+	if a := l.Upsert("bob", 50); a != l {
+		t.Error()
+	}
+
+	// dave 90, alice 50, bob 50, carol 50, erin 10
+	order := []string{"dave", "alice", "bob", "carol", "erin"}
+	for i, id := range order {
+		if r, ok := l.Rank(id); !ok || r != i {
+			t.Error(id, r, ok)
+		}
+		if got, _, ok := l.Select(i); !ok || got != id {
+			t.Error(i, got, ok)
+		}
+	}
+	if _, ok := l.Rank("zed"); ok {
+		t.Error()
+	}
+	if _, _, ok := l.Select(5); ok {
+		t.Error()
+	}
+	if _, _, ok := l.Select(-1); ok {
+		t.Error()
+	}
+
+	collect := func(seq func(yield func(string, int) bool)) (ids []string) {
+		for id := range seq {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	if ids := collect(l.TopN(3)); !slices.Equal(ids, order[:3]) {
+		t.Error(ids)
+	}
+	if ids := collect(l.Page(2, 10)); !slices.Equal(ids, order[2:]) {
+		t.Error(ids)
+	}
+	if ids := collect(l.AroundMe("bob", 1)); !slices.Equal(ids, order[1:4]) {
+		t.Error(ids)
+	}
+	if ids := collect(l.AroundMe("dave", 1)); !slices.Equal(ids, order[:2]) {
+		t.Error(ids)
+	}
+	if ids := collect(l.AroundMe("zed", 1)); ids != nil {
+		t.Error(ids)
+	}
+	if ids := collect(l.AroundMe("bob", math.MaxInt)); !slices.Equal(ids, order) {
+		t.Error(ids)
+	}
+	if ids := collect(l.Page(-1, 2)); !slices.Equal(ids, order[:2]) {
+		t.Error(ids)
+	}
+	for range l.Page(0, 5) {
+		break
+	}
+
+	if p, ok := l.PercentileRank("dave"); !ok || p != 90 {
+		t.Error(p, ok)
+	}
+	if p, ok := l.PercentileRank("erin"); !ok || p != 10 {
+		t.Error(p, ok)
+	}
+	if _, ok := l.PercentileRank("zed"); ok {
+		t.Error()
+	}
+
+	l = l.Remove("bob").Remove("dave").Remove("zed")
+	checkWeights(t, l.board)
+	if ids := collect(l.TopN(10)); !slices.Equal(ids, []string{"alice", "carol", "erin"}) {
+		t.Error(ids)
+	}
+}
+
+func TestLeaderboard_random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	var l Leaderboard[int, int]
+	for range 1000 {
+		l = l.Upsert(r.Intn(200), r.Intn(50))
+	}
+	for range 100 {
+		l = l.Remove(r.Intn(200))
+	}
+	checkWeights(t, l.board)
+
+	var last struct{ id, score int }
+	i := 0
+	for id, score := range l.TopN(l.Len()) {
+		if i > 0 && (score > last.score || score == last.score && id < last.id) {
+			t.Fatal(i, id, score)
+		}
+		if r, _ := l.Rank(id); r != i {
+			t.Fatal(i, r)
+		}
+		last.id, last.score = id, score
+		i++
+	}
+	if i != l.Len() {
+		t.Error(i)
+	}
+}
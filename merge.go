@@ -0,0 +1,111 @@
+package aa
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// Merger combines many trees at once.
+//
+// Trees are combined pairwise, smallest pair first.
+// Only adjacent trees are combined, so the order of the trees
+// determines which values win (or the order they're merged in).
+type Merger[K cmp.Ordered, V any] struct {
+	// Merge, if not nil, combines the values of keys found in two trees,
+	// with v1 from the earlier tree, and v2 from the later one.
+	Merge func(key K, v1, v2 V) V
+
+	// Parallel, if true, combines disjoint pairs of trees concurrently.
+	Parallel bool
+}
+
+// UnionAll returns the set union of trees, last value wins.
+func UnionAll[K cmp.Ordered, V any](trees ...*Tree[K, V]) *Tree[K, V] {
+	return Merger[K, V]{}.UnionAll(trees...)
+}
+
+// IntersectAll returns the set intersection of trees, first value wins.
+func IntersectAll[K cmp.Ordered, V any](trees ...*Tree[K, V]) *Tree[K, V] {
+	return Merger[K, V]{}.IntersectAll(trees...)
+}
+
+// UnionAll returns the set union of trees.
+// Unless m.Merge is set, last value wins.
+func (m Merger[K, V]) UnionAll(trees ...*Tree[K, V]) *Tree[K, V] {
+	trees = slices.DeleteFunc(slices.Clone(trees), func(t *Tree[K, V]) bool { return t == nil })
+	return m.combine(trees, func(t1, t2 *Tree[K, V]) *Tree[K, V] {
+		if m.Merge == nil {
+			return Union(t1, t2)
+		}
+		return UnionFunc(t1, t2, m.Merge)
+	})
+}
+
+// IntersectAll returns the set intersection of trees.
+// Unless m.Merge is set, first value wins.
+func (m Merger[K, V]) IntersectAll(trees ...*Tree[K, V]) *Tree[K, V] {
+	if slices.Contains(trees, nil) {
+		return nil
+	}
+	return m.combine(slices.Clone(trees), func(t1, t2 *Tree[K, V]) *Tree[K, V] {
+		if m.Merge == nil {
+			return Intersection(t1, t2)
+		}
+		return IntersectionFunc(t1, t2, m.Merge)
+	})
+}
+
+func (m Merger[K, V]) combine(trees []*Tree[K, V], op func(t1, t2 *Tree[K, V]) *Tree[K, V]) *Tree[K, V] {
+	for len(trees) > 1 {
+		// Adjacent pairs, smallest first.
+		pairs := make([]int, len(trees)-1)
+		for i := range pairs {
+			pairs[i] = i
+		}
+		slices.SortStableFunc(pairs, func(i, j int) int {
+			return cmp.Compare(
+				trees[i].Len()+trees[i+1].Len(),
+				trees[j].Len()+trees[j+1].Len())
+		})
+
+		if !m.Parallel {
+			i := pairs[0]
+			trees[i] = op(trees[i], trees[i+1])
+			trees = slices.Delete(trees, i+1, i+2)
+			continue
+		}
+
+		// Pick disjoint pairs, smallest first.
+		used := make([]bool, len(trees))
+		var wg sync.WaitGroup
+		for _, i := range pairs {
+			if used[i] || used[i+1] {
+				continue
+			}
+			used[i], used[i+1] = true, true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				trees[i] = op(trees[i], trees[i+1])
+			}()
+		}
+		wg.Wait()
+
+		// Drop the trees that were merged into their left neighbour.
+		n := 0
+		for i := 0; i < len(trees); i++ {
+			trees[n] = trees[i]
+			n++
+			if used[i] {
+				i++
+			}
+		}
+		trees = trees[:n]
+	}
+
+	if len(trees) == 0 {
+		return nil
+	}
+	return trees[0]
+}
@@ -0,0 +1,85 @@
+package aa
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+func TestUnionAll(t *testing.T) {
+	if a := UnionAll[int, int](); a != nil {
+		t.Error(a)
+	}
+
+	t1 := MakeMap(map[int]int{1: 1, 2: 1, 3: 1})
+	t2 := MakeMap(map[int]int{2: 2})
+	t3 := MakeMap(map[int]int{3: 3, 4: 3, 5: 3, 6: 3, 7: 3})
+	t4 := MakeMap(map[int]int{1: 4})
+
+	if a := UnionAll(t1); a != t1 {
+		t.Errorf("%p ≠ %p", a, t1)
+	}
+
+	want := map[int]int{1: 4, 2: 2, 3: 3, 4: 3, 5: 3, 6: 3, 7: 3}
+	for _, m := range []Merger[int, int]{{}, {Parallel: true}} {
+		a := m.UnionAll(t1, nil, t2, t3, t4)
+		a.check()
+		if !maps.Equal(a.Collect(), want) {
+			t.Error(a.Collect())
+		}
+	}
+
+	sum := func(_ int, v1, v2 int) int { return v1 + v2 }
+	want = map[int]int{1: 5, 2: 3, 3: 4, 4: 3, 5: 3, 6: 3, 7: 3}
+	for _, m := range []Merger[int, int]{{Merge: sum}, {Merge: sum, Parallel: true}} {
+		a := m.UnionAll(t1, t2, t3, t4)
+		a.check()
+		if !maps.Equal(a.Collect(), want) {
+			t.Error(a.Collect())
+		}
+	}
+}
+
+func TestIntersectAll(t *testing.T) {
+	t1 := MakeMap(map[int]int{1: 1, 2: 1, 3: 1, 4: 1})
+	t2 := MakeMap(map[int]int{2: 2, 3: 2, 4: 2})
+	t3 := MakeMap(map[int]int{0: 3, 3: 3, 4: 3, 5: 3, 6: 3})
+
+	if a := IntersectAll(t1, nil, t2); a != nil {
+		t.Error(a)
+	}
+	if a := IntersectAll(t1, t3, t2); !maps.Equal(a.Collect(), map[int]int{3: 1, 4: 1}) {
+		t.Error(a.Collect())
+	}
+
+	sum := func(_ int, v1, v2 int) int { return v1 + v2 }
+	for _, m := range []Merger[int, int]{{Merge: sum}, {Merge: sum, Parallel: true}} {
+		a := m.IntersectAll(t1, t2, t3)
+		a.check()
+		if !maps.Equal(a.Collect(), map[int]int{3: 6, 4: 6}) {
+			t.Error(a.Collect())
+		}
+	}
+}
+
+func TestUnionAll_random(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	trees := make([]*Tree[int, int], 20)
+	want := map[int]int{}
+	for i := range trees {
+		for range r.Intn(100) {
+			k := r.Intn(1000)
+			trees[i] = trees[i].Put(k, i)
+			want[k] = i
+		}
+	}
+
+	for _, m := range []Merger[int, int]{{}, {Parallel: true}} {
+		a := m.UnionAll(trees...)
+		a.check()
+		if !maps.Equal(a.Collect(), want) {
+			t.Error()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// MultiMap is an immutable sorted map from keys
+// to lists of values, in insertion order.
+//
+// The zero value for MultiMap is the empty multimap.
+//
+// The list of values for each key is itself a persistent tree,
+// so adding and removing values is O(log n), and nodes track
+// the number of values in their subtree, so indexing entries is too.
+type MultiMap[K cmp.Ordered, V any] struct {
+	tree *Tree[K, weighted[*Tree[uint64, V]]]
+}
+
+// Len returns the number of entries (key/value pairs) in this multimap.
+func (m MultiMap[K, V]) Len() int {
+	return totalWeight(m.tree)
+}
+
+// KeyLen returns the number of distinct keys in this multimap.
+func (m MultiMap[K, V]) KeyLen() int {
+	return m.tree.Len()
+}
+
+// Count returns the number of values for key.
+func (m MultiMap[K, V]) Count(key K) int {
+	if node := m.tree.lookup(key); node != nil {
+		return node.value.weight
+	}
+	return 0
+}
+
+// Has reports whether key has any values in this multimap.
+func (m MultiMap[K, V]) Has(key K) bool {
+	return m.tree.Has(key)
+}
+
+// Add returns a modified multimap with value
+// appended to the list of values for key.
+func (m MultiMap[K, V]) Add(key K, value V) MultiMap[K, V] {
+	return MultiMap[K, V]{reweigh(m.tree.Patch(key, func(node *Tree[K, weighted[*Tree[uint64, V]]]) (weighted[*Tree[uint64, V]], bool) {
+		var vals *Tree[uint64, V]
+		var seq uint64
+		if node != nil {
+			vals = node.value.val
+			seq = vals.Max().key + 1
+		}
+		vals = vals.Put(seq, value)
+		return weighted[*Tree[uint64, V]]{val: vals, weight: vals.Len()}, true
+	}))}
+}
+
+// RemoveOne returns a (possibly) modified multimap with the first value
+// for key removed from it, and the removed value;
+// found indicates whether key had any values.
+func (m MultiMap[K, V]) RemoveOne(key K) (_ MultiMap[K, V], value V, found bool) {
+	node := m.tree.lookup(key)
+	if node == nil {
+		return m, value, false
+	}
+	vals, first := node.value.val.DeleteMin()
+	if vals == nil {
+		return MultiMap[K, V]{reweigh(m.tree.Delete(key))}, first.value, true
+	}
+	return MultiMap[K, V]{reweigh(m.tree.Put(key, weighted[*Tree[uint64, V]]{val: vals, weight: vals.Len()}))}, first.value, true
+}
+
+// RemoveAll returns a (possibly) modified multimap
+// with all values for key removed from it.
+func (m MultiMap[K, V]) RemoveAll(key K) MultiMap[K, V] {
+	return MultiMap[K, V]{reweigh(m.tree.Delete(key))}
+}
+
+// Values returns an iterator for the values for key, in insertion order.
+func (m MultiMap[K, V]) Values(key K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if node := m.tree.lookup(key); node != nil {
+			for _, v := range node.value.val.Ascend() {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Keys returns an ascending iterator for the distinct keys in this multimap.
+func (m MultiMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.tree.Ascend() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator for the entries in this multimap,
+// in ascending key order, and then insertion order.
+func (m MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, w := range m.tree.Ascend() {
+			for _, v := range w.val.Ascend() {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Rank returns the number of entries in this multimap with keys less than key.
+func (m MultiMap[K, V]) Rank(key K) int {
+	return weightRank(m.tree, key)
+}
+
+// Select returns the entry at index i of this multimap,
+// in the order of All; ok is false if i is out of range.
+func (m MultiMap[K, V]) Select(i int) (key K, value V, ok bool) {
+	node, j := weightSelect(m.tree, i)
+	if node == nil {
+		return
+	}
+	return node.key, node.value.val.Select(j).value, true
+}
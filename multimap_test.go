@@ -0,0 +1,116 @@
+package aa
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	var m MultiMap[string, int]
+	if m.Len() != 0 || m.KeyLen() != 0 || m.Has("a") {
+		t.Error()
+	}
+
+	m = m.Add("b", 1).Add("a", 2).Add("b", 3).Add("c", 4).Add("b", 5)
+	m.tree.check()
+
+	if m.Len() != 5 || m.KeyLen() != 3 {
+		t.Error(m.Len(), m.KeyLen())
+	}
+	if n := m.Count("b"); n != 3 {
+		t.Error(n)
+	}
+	if n := m.Count("z"); n != 0 {
+		t.Error(n)
+	}
+	if v := slices.Collect(m.Values("b")); !slices.Equal(v, []int{1, 3, 5}) {
+		t.Error(v)
+	}
+	if k := slices.Collect(m.Keys()); !slices.Equal(k, []string{"a", "b", "c"}) {
+		t.Error(k)
+	}
+
+	var vals []int
+	for _, v := range m.All() {
+		vals = append(vals, v)
+	}
+	if !slices.Equal(vals, []int{2, 1, 3, 5, 4}) {
+		t.Error(vals)
+	}
+
+	if i := m.Rank("b"); i != 1 {
+		t.Error(i)
+	}
+	if i := m.Rank("c"); i != 4 {
+		t.Error(i)
+	}
+	if i := m.Rank("d"); i != 5 {
+		t.Error(i)
+	}
+	for i, want := range vals {
+		if _, v, ok := m.Select(i); !ok || v != want {
+			t.Error(i, v, ok)
+		}
+	}
+	if _, _, ok := m.Select(5); ok {
+		t.Error()
+	}
+	if _, _, ok := m.Select(-1); ok {
+		t.Error()
+	}
+
+	m, v, ok := m.RemoveOne("b")
+	if !ok || v != 1 || m.Count("b") != 2 || m.Len() != 4 {
+		t.Error(v, ok)
+	}
+	m, v, ok = m.RemoveOne("a")
+	if !ok || v != 2 || m.Has("a") || m.Len() != 3 {
+		t.Error(v, ok)
+	}
+	if _, _, ok := m.RemoveOne("a"); ok {
+		t.Error()
+	}
+
+	m = m.RemoveAll("b")
+	if m.Len() != 1 || m.Has("b") {
+		t.Error(m.Len())
+	}
+	m.tree.check()
+
+	for range m.All() {
+		break
+	}
+	for range m.Values("c") {
+		break
+	}
+	for range m.Keys() {
+		break
+	}
+}
+
+func TestMultiMap_weights(t *testing.T) {
+	var m MultiMap[int, int]
+	for i := range 1000 {
+		m = m.Add(i*7%100, i)
+	}
+	for i := 0; i < 100; i += 3 {
+		m = m.RemoveAll(i)
+		m, _, _ = m.RemoveOne(i + 1)
+	}
+	checkWeights(t, m.tree)
+}
+
+func checkWeights[K cmp.Ordered, T any](t *testing.T, tree *Tree[K, weighted[T]]) int {
+	if tree == nil {
+		return 0
+	}
+	total := tree.value.weight + checkWeights(t, tree.left) + checkWeights(t, tree.right)
+	if total != tree.value.total {
+		t.Fatalf("%d ≠ %d", total, tree.value.total)
+	}
+	if tree.value.left != weightOf(tree.left) || tree.value.right != weightOf(tree.right) {
+		t.Fatal("total summed from stale children")
+	}
+	return total
+}
@@ -0,0 +1,141 @@
+package aa
+
+import "iter"
+
+// Nearest finds the key in tree closest to x,
+// and returns the node for that key,
+// or nil if tree is empty.
+// Ties are broken in favor of the lesser key.
+//
+// As with Get, a NaN x finds a NaN key, if one exists;
+// otherwise, NaN keys are never the closest.
+func Nearest[K Number, V any](tree *Tree[K, V], x K) *Tree[K, V] {
+	if x != x {
+		return nan(tree)
+	}
+
+	lo := tree.Floor(x)
+	if lo != nil && lo.key != lo.key {
+		lo = nil
+	}
+	if lo != nil && lo.key == x {
+		return lo
+	}
+
+	hi := tree.Ceil(x)
+	switch {
+	case lo == nil:
+		return hi
+	case hi == nil:
+		return lo
+	case closer(hi.key-x, x-lo.key):
+		return hi
+	default:
+		return lo
+	}
+}
+
+// KNearest returns an iterator for the k keys in tree closest to x,
+// in order of increasing distance from x.
+// Ties are broken in favor of the lesser key.
+//
+// As with Get, a NaN x finds a NaN key, if one exists;
+// otherwise, NaN keys are never among the closest.
+func KNearest[K Number, V any](tree *Tree[K, V], x K, k int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if k <= 0 {
+			return
+		}
+		if x != x {
+			if node := nan(tree); node != nil {
+				yield(node.key, node.value)
+			}
+			return
+		}
+
+		nextLo, stop := iter.Pull2(tree.DescendFloor(x))
+		defer stop()
+		nextHi, stop := iter.Pull2(tree.AscendCeil(x))
+		defer stop()
+
+		klo, vlo, oklo := nextLo()
+		khi, vhi, okhi := nextHi()
+		if oklo && khi == x {
+			khi, vhi, okhi = nextHi() // Floor already found it.
+		}
+
+		for ; k > 0; k-- {
+			if oklo && klo != klo {
+				oklo = false
+			}
+			switch {
+			case okhi && (!oklo || closer(khi-x, x-klo)):
+				if !yield(khi, vhi) {
+					return
+				}
+				khi, vhi, okhi = nextHi()
+			case oklo:
+				if !yield(klo, vlo) {
+					return
+				}
+				klo, vlo, oklo = nextLo()
+			default:
+				return
+			}
+		}
+	}
+}
+
+// Within returns an ascending iterator for the keys in tree
+// within distance d of x: from x-d to x+d, inclusive.
+//
+// As with Get, a NaN x finds a NaN key, if one exists;
+// otherwise, NaN keys are never within any distance.
+func Within[K Number, V any](tree *Tree[K, V], x, d K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if x != x {
+			if node := nan(tree); node != nil {
+				yield(node.key, node.value)
+			}
+			return
+		}
+		if !(d >= 0) {
+			return
+		}
+
+		// Saturate on overflow.
+		lo, hi := x-d, x+d
+		seq := tree.AscendCeil(lo)
+		if lo > x {
+			seq = tree.Ascend()
+		}
+		for k, v := range seq {
+			if k != k {
+				continue
+			}
+			if hi >= x && k > hi || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// closer reports whether distance a is less than distance b.
+// Distances are never negative, unless they overflowed a signed type,
+// in which case they're larger than any distance that didn't.
+func closer[K Number](a, b K) bool {
+	if (a < 0) != (b < 0) {
+		return b < 0
+	}
+	return a < b
+}
+
+// nan returns the node for the NaN key in tree,
+// or nil if no such key exists.
+func nan[K Number, V any](tree *Tree[K, V]) *Tree[K, V] {
+	// NaN keys sort first; at most one exists.
+	if node := tree.Min(); node != nil && node.key != node.key {
+		return node
+	}
+	return nil
+}
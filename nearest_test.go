@@ -0,0 +1,121 @@
+package aa
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	tt := MakeSet(1, 3, 6, 10)
+
+	tests := []struct{ x, want int }{
+		{-5, 1}, {1, 1}, {2, 1}, {4, 3}, {5, 6}, {8, 6}, {9, 10}, {20, 10},
+	}
+	for _, tc := range tests {
+		if n := Nearest(tt, tc.x); n.Key() != tc.want {
+			t.Errorf("Nearest(%d) = %d, want %d", tc.x, n.Key(), tc.want)
+		}
+	}
+	if n := Nearest[int, struct{}](nil, 0); n != nil {
+		t.Error(n)
+	}
+
+	ext := MakeSet[int8](-128, 127)
+	if n := Nearest(ext, -1); n.Key() != -128 {
+		t.Error(n.Key())
+	}
+	if n := Nearest(ext, 0); n.Key() != 127 {
+		t.Error(n.Key())
+	}
+}
+
+func TestNearest_nan(t *testing.T) {
+	tt := MakeSet(math.NaN(), 1, 3)
+	if n := Nearest(tt, 0); n.Key() != 1 {
+		t.Error(n.Key())
+	}
+	if n := Nearest(tt, math.NaN()); !math.IsNaN(n.Key()) {
+		t.Error(n.Key())
+	}
+	if n := Nearest(MakeSet(1.0), math.NaN()); n != nil {
+		t.Error(n.Key())
+	}
+
+	var out []float64
+	for k := range KNearest(tt, 0, 5) {
+		out = append(out, k)
+	}
+	if !slices.Equal(out, []float64{1, 3}) {
+		t.Error(out)
+	}
+	for k := range KNearest(tt, math.NaN(), 5) {
+		if !math.IsNaN(k) {
+			t.Error(k)
+		}
+	}
+	for k := range Within(tt, 0, 10) {
+		if math.IsNaN(k) {
+			t.Error(k)
+		}
+	}
+	for k := range Within(tt, math.NaN(), 10) {
+		if !math.IsNaN(k) {
+			t.Error(k)
+		}
+	}
+}
+
+func TestKNearest(t *testing.T) {
+	tt := MakeSet(1, 3, 6, 10)
+
+	collect := func(x, k int) (out []int) {
+		for k := range KNearest(tt, x, k) {
+			out = append(out, k)
+		}
+		return out
+	}
+
+	if out := collect(4, 3); !slices.Equal(out, []int{3, 6, 1}) {
+		t.Error(out)
+	}
+	if out := collect(3, 10); !slices.Equal(out, []int{3, 1, 6, 10}) {
+		t.Error(out)
+	}
+	if out := collect(8, 2); !slices.Equal(out, []int{6, 10}) {
+		t.Error(out)
+	}
+	if out := collect(0, 0); out != nil {
+		t.Error(out)
+	}
+	for range KNearest(tt, 3, 3) {
+		break
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tt := MakeSet[uint8](0, 1, 3, 6, 10, 255)
+
+	collect := func(x, d uint8) (out []uint8) {
+		for k := range Within(tt, x, d) {
+			out = append(out, k)
+		}
+		return out
+	}
+
+	if out := collect(4, 2); !slices.Equal(out, []uint8{3, 6}) {
+		t.Error(out)
+	}
+	if out := collect(1, 5); !slices.Equal(out, []uint8{0, 1, 3, 6}) {
+		t.Error(out)
+	}
+	if out := collect(250, 10); !slices.Equal(out, []uint8{255}) {
+		t.Error(out)
+	}
+	for range Within(tt, 4, 10) {
+		break
+	}
+	for range Within(MakeSet(1.0), 1, -1) {
+		t.Error()
+	}
+}
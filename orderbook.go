@@ -0,0 +1,232 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Side is a side of an OrderBook.
+//
+// Note: OrderBook methods panic if given a Side other than Bid or Ask.
+type Side uint8
+
+const (
+	Bid Side = iota // Buy orders: the best price is the highest.
+	Ask             // Sell orders: the best price is the lowest.
+)
+
+func (s Side) check() {
+	if s != Bid && s != Ask {
+		panic("invalid side")
+	}
+}
+
+// Order is an order resting in an OrderBook.
+type Order[ID any] struct {
+	ID       ID
+	Quantity int
+}
+
+// Fill is a (partial) match between an incoming (taker) order,
+// and an order resting in an OrderBook (maker).
+type Fill[ID, Price any] struct {
+	Taker    ID
+	Maker    ID
+	Price    Price
+	Quantity int
+}
+
+// OrderBook is an immutable limit order book:
+// bids and asks, aggregated into price levels,
+// each with a FIFO queue of orders.
+//
+// The zero value for OrderBook is the empty order book.
+//
+// Price levels track the total quantity of their subtree,
+// so depth and liquidity queries are O(log n).
+type OrderBook[ID, Price cmp.Ordered] struct {
+	// Each price level is the FIFO queue of orders at that price,
+	// weighted by their total quantity.
+	sides  [2]*Tree[Price, weighted[*Tree[uint64, Order[ID]]]]
+	orders *Tree[ID, orderRef[Price]]
+	seq    uint64
+}
+
+type orderRef[Price any] struct {
+	side  Side
+	price Price
+	seq   uint64
+}
+
+// Len returns the number of orders resting in this order book.
+func (b OrderBook[ID, Price]) Len() int {
+	return b.orders.Len()
+}
+
+// Best returns the best price on a side of this order book,
+// and the total quantity at that price;
+// ok is false if that side is empty.
+func (b OrderBook[ID, Price]) Best(side Side) (price Price, quantity int, ok bool) {
+	side.check()
+	var node *Tree[Price, weighted[*Tree[uint64, Order[ID]]]]
+	if side == Bid {
+		node = b.sides[Bid].Max()
+	} else {
+		node = b.sides[Ask].Min()
+	}
+	if node == nil {
+		return
+	}
+	return node.key, node.value.weight, true
+}
+
+// Depth returns the total quantity on a side of this order book
+// at prices as good as, or better than, price.
+func (b OrderBook[ID, Price]) Depth(side Side, price Price) int {
+	side.check()
+	tree := b.sides[side]
+	if side == Bid {
+		return totalWeight(tree) - weightRank(tree, price)
+	}
+	qty := weightRank(tree, price)
+	if node := tree.lookup(price); node != nil {
+		qty += node.value.weight
+	}
+	return qty
+}
+
+// PriceFor returns the worst price reached when taking quantity
+// from a side of this order book, starting at the best price;
+// ok is false if that side lacks the quantity.
+func (b OrderBook[ID, Price]) PriceFor(side Side, quantity int) (price Price, ok bool) {
+	side.check()
+	tree := b.sides[side]
+	if quantity <= 0 || quantity > totalWeight(tree) {
+		return
+	}
+	var node *Tree[Price, weighted[*Tree[uint64, Order[ID]]]]
+	if side == Bid {
+		node, _ = weightSelect(tree, totalWeight(tree)-quantity)
+	} else {
+		node, _ = weightSelect(tree, quantity-1)
+	}
+	return node.key, true
+}
+
+// Levels returns an iterator for the price levels on a side of this order book,
+// and the total quantity at each price, from the best price to the worst.
+func (b OrderBook[ID, Price]) Levels(side Side) iter.Seq2[Price, int] {
+	side.check()
+	return func(yield func(Price, int) bool) {
+		seq := b.sides[side].Ascend()
+		if side == Bid {
+			seq = b.sides[side].Descend()
+		}
+		for p, l := range seq {
+			if !yield(p, l.weight) {
+				return
+			}
+		}
+	}
+}
+
+// Orders returns an iterator for the orders at a price level on a side of this order book,
+// in FIFO order.
+func (b OrderBook[ID, Price]) Orders(side Side, price Price) iter.Seq[Order[ID]] {
+	side.check()
+	return func(yield func(Order[ID]) bool) {
+		if node := b.sides[side].lookup(price); node != nil {
+			for _, o := range node.value.val.Ascend() {
+				if !yield(o) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Limit returns a modified order book after submitting a limit order:
+// the order is matched against the opposite side of this order book,
+// while prices cross, and any remaining quantity rests at price.
+// It also returns the fills, in the order they happened.
+//
+// Note: Limit panics if quantity is not positive,
+// or if an order with the same id is resting in this order book.
+func (b OrderBook[ID, Price]) Limit(side Side, id ID, price Price, quantity int) (_ OrderBook[ID, Price], fills []Fill[ID, Price]) {
+	side.check()
+	if quantity <= 0 {
+		panic("quantity must be positive")
+	}
+	if b.orders.Has(id) {
+		panic("duplicate order ID")
+	}
+
+	other := 1 - side
+	for quantity > 0 {
+		best, _, ok := b.Best(other)
+		if !ok || side == Bid && cmp.Less(price, best) || side == Ask && cmp.Less(best, price) {
+			break
+		}
+
+		lvl := b.sides[other].lookup(best).value
+		orders := lvl.val
+		for quantity > 0 && orders != nil {
+			first := orders.Min()
+			maker := first.value
+			qty := min(quantity, maker.Quantity)
+			fills = append(fills, Fill[ID, Price]{Taker: id, Maker: maker.ID, Price: best, Quantity: qty})
+			quantity -= qty
+			lvl.weight -= qty
+
+			if qty == maker.Quantity {
+				orders, _ = orders.DeleteMin()
+				b.orders = b.orders.Delete(maker.ID)
+			} else {
+				maker.Quantity -= qty
+				orders = orders.Put(first.key, maker)
+			}
+		}
+
+		if orders == nil {
+			b.sides[other] = reweigh(b.sides[other].Delete(best))
+		} else {
+			b.sides[other] = reweigh(b.sides[other].Put(best, weighted[*Tree[uint64, Order[ID]]]{val: orders, weight: lvl.weight}))
+		}
+	}
+
+	if quantity > 0 {
+		seq := b.seq
+		b.seq++
+		b.orders = b.orders.Put(id, orderRef[Price]{side, price, seq})
+		b.sides[side] = reweigh(b.sides[side].Patch(price, func(node *Tree[Price, weighted[*Tree[uint64, Order[ID]]]]) (weighted[*Tree[uint64, Order[ID]]], bool) {
+			lvl := weighted[*Tree[uint64, Order[ID]]]{weight: quantity}
+			if node != nil {
+				lvl.val = node.value.val
+				lvl.weight += node.value.weight
+			}
+			lvl.val = lvl.val.Put(seq, Order[ID]{id, quantity})
+			return lvl, true
+		}))
+	}
+	return b, fills
+}
+
+// Cancel returns a (possibly) modified order book with the order for id removed from it;
+// found indicates whether the order was resting in this order book.
+func (b OrderBook[ID, Price]) Cancel(id ID) (_ OrderBook[ID, Price], found bool) {
+	orders, ref, found := b.orders.LoadAndDelete(id)
+	if !found {
+		return b, false
+	}
+	b.orders = orders
+
+	tree := b.sides[ref.side]
+	lvl := tree.lookup(ref.price).value
+	queue, order, _ := lvl.val.LoadAndDelete(ref.seq)
+	if queue == nil {
+		b.sides[ref.side] = reweigh(tree.Delete(ref.price))
+	} else {
+		b.sides[ref.side] = reweigh(tree.Put(ref.price, weighted[*Tree[uint64, Order[ID]]]{val: queue, weight: lvl.weight - order.Quantity}))
+	}
+	return b, true
+}
@@ -0,0 +1,143 @@
+package aa
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderBook(t *testing.T) {
+	var b OrderBook[string, int]
+	if _, _, ok := b.Best(Bid); ok {
+		t.Error()
+	}
+
+	b, _ = b.Limit(Bid, "b1", 99, 10)
+	b, _ = b.Limit(Bid, "b2", 98, 5)
+	b, _ = b.Limit(Bid, "b3", 99, 7)
+	b, _ = b.Limit(Ask, "a1", 101, 4)
+	b, _ = b.Limit(Ask, "a2", 102, 6)
+	b, _ = b.Limit(Ask, "a3", 101, 3)
+	snapshot := b
+
+	if p, q, ok := b.Best(Bid); !ok || p != 99 || q != 17 {
+		t.Error(p, q, ok)
+	}
+	if p, q, ok := b.Best(Ask); !ok || p != 101 || q != 7 {
+		t.Error(p, q, ok)
+	}
+	var prices []int
+	for p := range b.Levels(Bid) {
+		prices = append(prices, p)
+	}
+	if !slices.Equal(prices, []int{99, 98}) {
+		t.Error(prices)
+	}
+
+	if d := b.Depth(Bid, 98); d != 22 {
+		t.Error(d)
+	}
+	if d := b.Depth(Bid, 99); d != 17 {
+		t.Error(d)
+	}
+	if d := b.Depth(Ask, 101); d != 7 {
+		t.Error(d)
+	}
+	if d := b.Depth(Ask, 150); d != 13 {
+		t.Error(d)
+	}
+
+	if p, ok := b.PriceFor(Ask, 7); !ok || p != 101 {
+		t.Error(p, ok)
+	}
+	if p, ok := b.PriceFor(Ask, 8); !ok || p != 102 {
+		t.Error(p, ok)
+	}
+	if _, ok := b.PriceFor(Ask, 14); ok {
+		t.Error()
+	}
+	if p, ok := b.PriceFor(Bid, 18); !ok || p != 98 {
+		t.Error(p, ok)
+	}
+
+	// Cross the spread.
+	b, fills := b.Limit(Bid, "b4", 102, 9)
+	want := []Fill[string, int]{
+		{"b4", "a1", 101, 4},
+		{"b4", "a3", 101, 3},
+		{"b4", "a2", 102, 2},
+	}
+	if !slices.Equal(fills, want) {
+		t.Error(fills)
+	}
+	if p, q, ok := b.Best(Ask); !ok || p != 102 || q != 4 {
+		t.Error(p, q, ok)
+	}
+	if b.Len() != 4 {
+		t.Error(b.Len())
+	}
+	checkWeights(t, b.sides[Ask])
+
+	// Sweep and rest.
+	b, fills = b.Limit(Ask, "a4", 98, 25)
+	if len(fills) != 3 || fills[0].Maker != "b1" || fills[1].Maker != "b3" || fills[2].Maker != "b2" {
+		t.Error(fills)
+	}
+	if p, q, ok := b.Best(Ask); !ok || p != 98 || q != 3 {
+		t.Error(p, q, ok)
+	}
+	if _, _, ok := b.Best(Bid); ok {
+		t.Error()
+	}
+
+	// Snapshots are immutable.
+	if p, q, _ := snapshot.Best(Bid); p != 99 || q != 17 {
+		t.Error(p, q)
+	}
+
+	snapshot, ok := snapshot.Cancel("b1")
+	if !ok || snapshot.Depth(Bid, 98) != 12 {
+		t.Error(snapshot.Depth(Bid, 98))
+	}
+	var ids []string
+	for o := range snapshot.Orders(Bid, 99) {
+		ids = append(ids, o.ID)
+	}
+	if !slices.Equal(ids, []string{"b3"}) {
+		t.Error(ids)
+	}
+	snapshot, _ = snapshot.Cancel("b3")
+	if p, _, _ := snapshot.Best(Bid); p != 98 {
+		t.Error(p)
+	}
+	if _, ok := snapshot.Cancel("b3"); ok {
+		t.Error()
+	}
+	checkWeights(t, snapshot.sides[Bid])
+
+	for range snapshot.Levels(Ask) {
+		break
+	}
+	for range snapshot.Orders(Ask, 102) {
+		break
+	}
+
+	for _, f := range []func(){
+		func() { b.Limit(Bid, "a2", 1, 1) },
+		func() { b.Limit(Bid, "x", 1, 0) },
+		func() { b.Limit(2, "x", 1, 1) },
+		func() { b.Best(2) },
+		func() { b.Depth(2, 1) },
+		func() { b.PriceFor(2, 1) },
+		func() { b.Levels(2) },
+		func() { b.Orders(2, 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
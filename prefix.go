@@ -0,0 +1,97 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+	"strings"
+)
+
+// PrefixEnd returns the least string greater than
+// every string that starts with prefix,
+// the exclusive upper bound of the range of keys with that prefix.
+//
+// There is no such string if prefix is empty or all 0xff bytes,
+// in which case ok is false: the range is unbounded.
+func PrefixEnd[S ~string | ~[]byte](prefix S) (end S, ok bool) {
+	b := []byte(string(prefix)) // copy
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0xff {
+			b[i]++
+			return S(b[:i+1]), true
+		}
+	}
+	return end, false
+}
+
+// AscendPrefix returns an ascending iterator for the keys in tree
+// that start with prefix.
+func AscendPrefix[K ~string, V any](tree *Tree[K, V], prefix K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range tree.AscendCeil(prefix) {
+			if !strings.HasPrefix(string(k), string(prefix)) || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// CountPrefix returns the number of keys in tree that start with prefix.
+func CountPrefix[K ~string, V any](tree *Tree[K, V], prefix K) int {
+	hi := tree.Len()
+	if end, ok := PrefixEnd(prefix); ok {
+		hi = tree.Rank(end)
+	}
+	return hi - tree.Rank(prefix)
+}
+
+// Prefix returns a tree with the keys in tree that start with prefix.
+func Prefix[K ~string, V any](tree *Tree[K, V], prefix K) *Tree[K, V] {
+	_, mid, _ := splitPrefix(tree, prefix)
+	return mid
+}
+
+// DeletePrefix returns a (possibly) modified tree
+// with all keys that start with prefix removed from it.
+func DeletePrefix[K ~string, V any](tree *Tree[K, V], prefix K) *Tree[K, V] {
+	left, mid, right := splitPrefix(tree, prefix)
+	if mid == nil {
+		return tree
+	}
+	return join2(left, right)
+}
+
+// ReplacePrefix returns a modified tree with all keys that start with prefix
+// replaced by the keys in subtree.
+//
+// Note: ReplacePrefix panics if any key in subtree doesn't start with prefix.
+func ReplacePrefix[K ~string, V any](tree *Tree[K, V], prefix K, subtree *Tree[K, V]) *Tree[K, V] {
+	if subtree != nil {
+		if !strings.HasPrefix(string(subtree.Min().key), string(prefix)) ||
+			!strings.HasPrefix(string(subtree.Max().key), string(prefix)) {
+			panic("subtree keys must start with prefix")
+		}
+	}
+	left, _, right := splitPrefix(tree, prefix)
+	return join2(join2(left, subtree), right)
+}
+
+// splitPrefix partitions tree into a left tree with keys less than prefix,
+// a middle tree with keys that start with prefix,
+// and a right tree with keys greater than those.
+func splitPrefix[K ~string, V any](tree *Tree[K, V], prefix K) (left, mid, right *Tree[K, V]) {
+	left, mid = splitLess(tree, prefix)
+	if end, ok := PrefixEnd(prefix); ok {
+		mid, right = splitLess(mid, end)
+	}
+	return left, mid, right
+}
+
+// splitLess partitions tree into a left tree with keys less than key,
+// and a right tree with keys greater-than or equal-to key.
+func splitLess[K cmp.Ordered, V any](tree *Tree[K, V], key K) (left, right *Tree[K, V]) {
+	left, node, right := tree.Split(key)
+	if node != nil {
+		right = join(nil, node, right)
+	}
+	return left, right
+}
@@ -0,0 +1,80 @@
+package aa
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix string
+		end    string
+		ok     bool
+	}{
+		{"", "", false},
+		{"a", "b", true},
+		{"ab", "ac", true},
+		{"a\xff", "b", true},
+		{"a\xff\xff", "b", true},
+		{"\xff\xff", "", false},
+	}
+	for _, tt := range tests {
+		end, ok := PrefixEnd(tt.prefix)
+		if end != tt.end || ok != tt.ok {
+			t.Errorf("PrefixEnd(%q) = %q, %v", tt.prefix, end, ok)
+		}
+	}
+
+	b := []byte("a\xff")
+	if end, ok := PrefixEnd(b); !ok || string(end) != "b" || string(b) != "a\xff" {
+		t.Error(end, ok)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	tt := MakeSet("a", "a/b", "a/b/c", "a/c", "a\xff", "a\xff\xff", "b", "b/a", "\xff", "\xff\xff")
+	tt.check()
+
+	var out []string
+	for k := range AscendPrefix(tt, "a/") {
+		out = append(out, k)
+	}
+	if !slices.Equal(out, []string{"a/b", "a/b/c", "a/c"}) {
+		t.Error(out)
+	}
+
+	if n := CountPrefix(tt, "a"); n != 6 {
+		t.Error(n)
+	}
+	if n := CountPrefix(tt, "a\xff"); n != 2 {
+		t.Error(n)
+	}
+	if n := CountPrefix(tt, "\xff"); n != 2 {
+		t.Error(n)
+	}
+	if n := CountPrefix(tt, ""); n != tt.Len() {
+		t.Error(n)
+	}
+	if n := Prefix(tt, "a/b").Len(); n != 2 {
+		t.Error(n)
+	}
+
+	del := DeletePrefix(tt, "a")
+	del.check()
+	if n := del.Len(); n != 4 || del.Has("a/b") || !del.Has("b") {
+		t.Error(n)
+	}
+	if a := DeletePrefix(tt, "c"); a != tt {
+		t.Errorf("%p ≠ %p", a, tt)
+	}
+
+	rep := ReplacePrefix(tt, "a/", MakeSet("a/x", "a/y"))
+	rep.check()
+	if n := rep.Len(); n != 9 || rep.Has("a/b") || !rep.Has("a/y") || !rep.Has("a\xff") {
+		t.Error(n)
+	}
+
+	defer func() { _ = recover() }()
+	ReplacePrefix(tt, "a/", MakeSet("b"))
+	t.Error()
+}
@@ -0,0 +1,96 @@
+package aa
+
+import "math"
+
+// Number is a constraint that permits any numeric key type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Interpolation is the method used to compute quantiles
+// that fall between two keys.
+type Interpolation int
+
+const (
+	// NearestRank picks the least key such that a fraction q
+	// of the keys are less-than or equal-to it.
+	NearestRank Interpolation = iota
+	// Linear interpolates linearly between the two closest keys,
+	// with the least key at q=0, and the greatest at q=1.
+	Linear
+)
+
+// Quantile returns the q-quantile of the keys in tree,
+// for 0 ≤ q ≤ 1, using the given interpolation method.
+//
+// Keys are unique, so each counts once.
+// A NaN key is ignored.
+//
+// Note: the quantile of an empty tree,
+// or for q outside [0, 1], is NaN.
+func Quantile[K Number, V any](tree *Tree[K, V], q float64, method Interpolation) float64 {
+	off := 0
+	if nan(tree) != nil {
+		off = 1
+	}
+	return quantile(tree.Len()-off, q, method, func(i int) float64 {
+		return float64(tree.Select(off + i).key)
+	})
+}
+
+// quantile computes the q-quantile of n sorted keys,
+// where key(i) returns the key at index i.
+func quantile(n int, q float64, method Interpolation, key func(i int) float64) float64 {
+	if n <= 0 || !(0 <= q && q <= 1) {
+		return math.NaN()
+	}
+
+	switch method {
+	case NearestRank:
+		i := max(1, int(math.Ceil(exact(q*float64(n))))) - 1
+		return key(i)
+
+	case Linear:
+		h := exact(q * float64(n-1))
+		i := int(h)
+		x := key(i)
+		if f := h - float64(i); f > 0 {
+			y := key(i + 1)
+			x += f * (y - x)
+		}
+		return x
+
+	default:
+		panic("invalid interpolation method")
+	}
+}
+
+// exact rounds x to the nearest integer
+// if it's within rounding error of it,
+// so that, e.g., 0.07×100 is 7 rather than 7.000000000000001.
+func exact(x float64) float64 {
+	if r := math.Round(x); math.Abs(x-r) <= 0x1p-40*r {
+		return r
+	}
+	return x
+}
+
+// Median returns the median of the keys in tree,
+// linearly interpolated if tree has an even number of keys.
+//
+// Note: the median of an empty tree is NaN.
+func Median[K Number, V any](tree *Tree[K, V]) float64 {
+	return Quantile(tree, 0.5, Linear)
+}
+
+// Percentiles returns the p-th percentiles of the keys in tree,
+// for each 0 ≤ p ≤ 100, using the given interpolation method.
+func Percentiles[K Number, V any](tree *Tree[K, V], method Interpolation, ps ...float64) []float64 {
+	res := make([]float64, len(ps))
+	for i, p := range ps {
+		res[i] = Quantile(tree, p/100, method)
+	}
+	return res
+}
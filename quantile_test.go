@@ -0,0 +1,83 @@
+package aa
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	tt := MakeSet(15, 20, 35, 40, 50)
+
+	tests := []struct {
+		q      float64
+		method Interpolation
+		want   float64
+	}{
+		{0, NearestRank, 15},
+		{0.05, NearestRank, 15},
+		{0.3, NearestRank, 20},
+		{0.4, NearestRank, 20},
+		{0.5, NearestRank, 35},
+		{1, NearestRank, 50},
+		{0, Linear, 15},
+		{0.5, Linear, 35},
+		{0.4, Linear, 29},
+		{0.75, Linear, 40},
+		{1, Linear, 50},
+	}
+	for _, tc := range tests {
+		if got := Quantile(tt, tc.q, tc.method); got != tc.want {
+			t.Errorf("Quantile(%v, %v) = %v, want %v", tc.q, tc.method, got, tc.want)
+		}
+	}
+
+	if got := Quantile(tt, -0.1, Linear); !math.IsNaN(got) {
+		t.Error(got)
+	}
+	if got := Quantile(tt, math.NaN(), Linear); !math.IsNaN(got) {
+		t.Error(got)
+	}
+	if got := Median[int, struct{}](nil); !math.IsNaN(got) {
+		t.Error(got)
+	}
+	if got := Median(MakeSet(1, 2, 3, 4)); got != 2.5 {
+		t.Error(got)
+	}
+	if got := Percentiles(tt, NearestRank, 50, 100); !slices.Equal(got, []float64{35, 50}) {
+		t.Error(got)
+	}
+
+	// Ranks that are whole numbers are exact.
+	var hundred, hundredOne *Tree[int, struct{}]
+	for i := 1; i <= 100; i++ {
+		hundred = hundred.Add(i)
+	}
+	hundredOne = hundred.Add(101)
+	if got := Percentiles(hundred, NearestRank, 7, 14, 28, 57); !slices.Equal(got, []float64{7, 14, 28, 57}) {
+		t.Error(got)
+	}
+	if got := Percentiles(hundredOne, Linear, 7, 14, 28, 57); !slices.Equal(got, []float64{8, 15, 29, 58}) {
+		t.Error(got)
+	}
+	if got := Quantile(hundred, 0.07, NearestRank); got != 7 {
+		t.Error(got)
+	}
+
+	defer func() { _ = recover() }()
+	Quantile(tt, 0.5, -1)
+	t.Error()
+}
+
+func TestQuantile_nan(t *testing.T) {
+	tt := MakeSet(math.NaN(), 1, 2, 3)
+	if got := Median(tt); got != 2 {
+		t.Error(got)
+	}
+	if got := Quantile(tt, 0, NearestRank); got != 1 {
+		t.Error(got)
+	}
+	if got := Median(MakeSet(math.NaN())); !math.IsNaN(got) {
+		t.Error(got)
+	}
+}
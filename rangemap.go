@@ -0,0 +1,92 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// RangeMap is an immutable map from disjoint half-open intervals to values.
+//
+// The zero value for RangeMap is the empty map.
+//
+// Unlike IntervalSet, adjacent intervals are never coalesced,
+// even if they map to the same value.
+type RangeMap[K cmp.Ordered, V any] struct {
+	tree *Tree[K, span[K, V]]
+}
+
+// Len returns the number of intervals in this map.
+func (m RangeMap[K, V]) Len() int {
+	return m.tree.Len()
+}
+
+// All returns an ascending iterator for the intervals in this map,
+// and their values.
+func (m RangeMap[K, V]) All() iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		for lo, sp := range m.tree.Ascend() {
+			if !yield(Interval[K]{lo, sp.hi}, sp.value) {
+				return
+			}
+		}
+	}
+}
+
+// Get returns the value for the interval that contains x;
+// found is false if no such interval exists.
+func (m RangeMap[K, V]) Get(x K) (value V, found bool) {
+	if node := spanAt(m.tree, x); node != nil {
+		return node.value.value, true
+	}
+	return
+}
+
+// Entry returns the interval that contains x, and its value;
+// found is false if no such interval exists.
+func (m RangeMap[K, V]) Entry(x K) (interval Interval[K], value V, found bool) {
+	if node := spanAt(m.tree, x); node != nil {
+		return Interval[K]{node.key, node.value.hi}, node.value.value, true
+	}
+	return
+}
+
+// Put returns a (possibly) modified map with [lo, hi) mapped to value,
+// overwriting, and truncating or splitting, any intervals it overlaps.
+// An empty interval leaves the map unchanged.
+func (m RangeMap[K, V]) Put(lo, hi K, value V) RangeMap[K, V] {
+	if !cmp.Less(lo, hi) {
+		return m
+	}
+	left, right := cut(m.tree, lo, hi)
+	node := &Tree[K, span[K, V]]{key: lo, value: span[K, V]{hi: hi, value: value}}
+	return RangeMap[K, V]{join(left, node, right)}
+}
+
+// Remove returns a (possibly) modified map with [lo, hi) unmapped,
+// truncating, or splitting, any intervals it overlaps.
+func (m RangeMap[K, V]) Remove(lo, hi K) RangeMap[K, V] {
+	if !cmp.Less(lo, hi) {
+		return m
+	}
+	left, right := cut(m.tree, lo, hi)
+	return RangeMap[K, V]{join2(left, right)}
+}
+
+// Sub returns a map with the intervals in this map
+// truncated to [lo, hi).
+func (m RangeMap[K, V]) Sub(lo, hi K) RangeMap[K, V] {
+	return RangeMap[K, V]{clip(m.tree, lo, hi)}
+}
+
+// Overlapping returns an ascending iterator for the intervals
+// in this map that overlap [lo, hi), and their values.
+// Intervals are not truncated.
+func (m RangeMap[K, V]) Overlapping(lo, hi K) iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		for k, sp := range between(m.tree, lo, hi) {
+			if !yield(Interval[K]{k, sp.hi}, sp.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package aa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRangeMap(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for range 200 {
+		var m RangeMap[int, int]
+		var want [64]int // 0 is unmapped
+		for i := range r.Intn(20) {
+			lo, hi := r.Intn(65), r.Intn(65)
+			v := i + 1
+			if r.Intn(3) == 0 {
+				m = m.Remove(lo, hi)
+				v = 0
+			} else {
+				m = m.Put(lo, hi, v)
+			}
+			for x := lo; x < hi; x++ {
+				want[x] = v
+			}
+		}
+		m.tree.check()
+
+		last := -1
+		for i, v := range m.All() {
+			if i.Lo < last || i.Lo >= i.Hi {
+				t.Fatalf("bad interval %v after %d", i, last)
+			}
+			for x := i.Lo; x < i.Hi; x++ {
+				if want[x] != v {
+					t.Fatal(x, want[x], v)
+				}
+			}
+			last = i.Hi
+		}
+		for x := range 64 {
+			v, ok := m.Get(x)
+			if ok != (want[x] != 0) || v != want[x] {
+				t.Fatal(x, want[x], v)
+			}
+			if i, v, ok := m.Entry(x); ok && (i.Lo > x || x >= i.Hi || v != want[x]) {
+				t.Fatal(x, i)
+			}
+		}
+
+		lo, hi := r.Intn(65), r.Intn(65)
+		sub := m.Sub(lo, hi)
+		sub.tree.check()
+		for x := range 64 {
+			v, _ := sub.Get(x)
+			if lo <= x && x < hi && v != want[x] || (x < lo || x >= hi) && v != 0 {
+				t.Fatal("sub", x, lo, hi)
+			}
+		}
+		n := 0
+		for i := range m.Overlapping(lo, hi) {
+			if i.Hi <= lo || i.Lo >= hi {
+				t.Fatal("overlapping", i, lo, hi)
+			}
+			n++
+		}
+		if n != sub.Len() {
+			t.Fatal("overlapping", n, sub.Len())
+		}
+	}
+}
+
+func TestRangeMap_split(t *testing.T) {
+	var m RangeMap[int, string]
+	m = m.Put(0, 10, "a").Put(3, 5, "b").Put(5, 7, "b")
+	if m.Len() != 4 {
+		t.Error(m.Len())
+	}
+	if i, v, _ := m.Entry(8); i != (Interval[int]{7, 10}) || v != "a" {
+		t.Error(i, v)
+	}
+	if _, ok := m.Get(10); ok {
+		t.Error()
+	}
+	if u := m.Put(5, 5, "c"); u.tree != m.tree {
+		t.Error("copied")
+	}
+
+	for range m.All() {
+		break
+	}
+	for range m.Overlapping(0, 10) {
+		break
+	}
+}
@@ -0,0 +1,583 @@
+// Package reconcile implements range-based set reconciliation of trees.
+//
+// Two peers holding similar trees exchange the content hashes
+// of matching key ranges, recursively bisecting the ranges that differ,
+// until they are small enough to exchange their entries.
+// Only entries in differing ranges cross the wire,
+// and the number of round trips is logarithmic in the size of the trees.
+//
+// One peer calls [Peer.Initiate], the other [Peer.Respond],
+// over any [io.ReadWriter] that connects them:
+// a [net.Conn], a [net.Pipe], stdin/stdout, etc.
+package reconcile
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/ncruces/aa"
+)
+
+// Codec encodes and decodes keys or values.
+//
+// Encodings must be deterministic:
+// equal values must have equal encodings.
+type Codec[T any] struct {
+	// Append appends the encoding of v to buf, and returns the extended buffer.
+	Append func(buf []byte, v T) []byte
+	// Decode decodes a value from its encoding.
+	Decode func(buf []byte) (T, error)
+}
+
+// Peer reconciles trees with keys of type K and values of type V.
+type Peer[K cmp.Ordered, V any] struct {
+	Key   Codec[K]
+	Value Codec[V]
+
+	// Threshold is the number of entries in a range below which
+	// peers exchange entries, rather than bisecting the range.
+	// The default is 16.
+	Threshold int
+
+	// HashCache is the number of subtree hashes cached across sessions,
+	// so that reconciling a tree again, or a later version of it,
+	// doesn't rehash it all. The default is 65536.
+	// See [aa.Hasher] for the costs involved.
+	HashCache int
+
+	mtx    sync.Mutex
+	hasher *aa.Hasher[K, V]
+}
+
+// Diff is the result of reconciling two trees.
+//
+// Values are compared by their encoding.
+// The merged tree is aa.Union(local, diff.Remote)
+// if remote values should win, or aa.Union(diff.Remote, local) otherwise.
+type Diff[K cmp.Ordered, V any] struct {
+	// Local has the entries only in the local tree,
+	// or with a different value in the remote tree.
+	Local *aa.Tree[K, V]
+	// Remote has the entries only in the remote tree,
+	// or with a different value in the local tree.
+	Remote *aa.Tree[K, V]
+}
+
+// ErrProtocol is returned when a peer sends a malformed message.
+var ErrProtocol = errors.New("reconcile: protocol error")
+
+// ErrFrameTooLarge is returned when a message
+// is too large to send in a single frame.
+var ErrFrameTooLarge = errors.New("reconcile: frame too large")
+
+const (
+	msgRanges = iota + 1
+	msgReply
+	msgItems
+	msgMoreItems // msgItems, to be followed by more
+)
+
+const (
+	replySame = iota
+	replyItems
+	replySplit
+)
+
+const maxFrame = 1 << 30
+
+// Items messages are split into frames of about this size.
+const itemsFrame = 1 << 20
+
+// Initiate reconciles tree with the tree of the peer calling Respond on
+// the other end of rw, and returns their differences.
+func (p *Peer[K, V]) Initiate(rw io.ReadWriter, tree *aa.Tree[K, V]) (Diff[K, V], error) {
+	s := p.newSession(tree)
+
+	// Ranges the peer sent entries for, and those entries.
+	var done []span[K]
+	var theirs [][]entry[K, V]
+
+	pending := []span[K]{{}}
+	for len(pending) > 0 {
+		buf := []byte{msgRanges}
+		buf = binary.AppendUvarint(buf, uint64(len(pending)))
+		for _, r := range pending {
+			buf = s.appendSpan(buf, r)
+			buf = binary.AppendUvarint(buf, uint64(s.count(r)))
+			buf = binary.BigEndian.AppendUint64(buf, s.hash(r))
+		}
+		if err := writeFrame(rw, buf); err != nil {
+			return Diff[K, V]{}, err
+		}
+
+		msg, err := readFrame(rw, msgReply)
+		if err != nil {
+			return Diff[K, V]{}, err
+		}
+
+		var next []span[K]
+		for _, r := range pending {
+			switch tag, err := msg.byte(); {
+			case err != nil:
+				return Diff[K, V]{}, err
+
+			case tag == replySame:
+				continue
+
+			case tag == replyItems:
+				items, err := s.readEntries(msg)
+				if err != nil {
+					return Diff[K, V]{}, err
+				}
+				done = append(done, r)
+				theirs = append(theirs, items)
+
+			case tag == replySplit:
+				mid, err := s.readKey(msg)
+				if err != nil {
+					return Diff[K, V]{}, err
+				}
+				if !r.contains(mid) || r.lo.ok && mid == r.lo.key {
+					return Diff[K, V]{}, ErrProtocol
+				}
+				next = append(next,
+					span[K]{r.lo, bound[K]{mid, true}},
+					span[K]{bound[K]{mid, true}, r.hi})
+
+			default:
+				return Diff[K, V]{}, ErrProtocol
+			}
+		}
+		if len(msg.buf) != 0 {
+			return Diff[K, V]{}, ErrProtocol
+		}
+		pending = next
+	}
+
+	// Send our entries for the ranges the peer sent entries for.
+	if err := s.writeItems(rw, done); err != nil {
+		return Diff[K, V]{}, err
+	}
+
+	var diff Diff[K, V]
+	for i, r := range done {
+		s.diff(&diff, r, theirs[i])
+	}
+	return diff, nil
+}
+
+// Respond reconciles tree with the tree of the peer calling Initiate on
+// the other end of rw, and returns their differences.
+func (p *Peer[K, V]) Respond(rw io.ReadWriter, tree *aa.Tree[K, V]) (Diff[K, V], error) {
+	s := p.newSession(tree)
+
+	var diff Diff[K, V]
+	types := []byte{msgRanges, msgItems, msgMoreItems}
+	for {
+		msg, err := readFrame(rw, types...)
+		if err != nil {
+			return Diff[K, V]{}, err
+		}
+
+		n, err := msg.uvarint()
+		if err != nil {
+			return Diff[K, V]{}, err
+		}
+
+		if msg.typ != msgRanges {
+			for range n {
+				r, err := s.readSpan(msg)
+				if err != nil {
+					return Diff[K, V]{}, err
+				}
+				items, err := s.readEntries(msg)
+				if err != nil {
+					return Diff[K, V]{}, err
+				}
+				s.diff(&diff, r, items)
+			}
+			if len(msg.buf) != 0 {
+				return Diff[K, V]{}, ErrProtocol
+			}
+			if msg.typ == msgItems {
+				return diff, nil
+			}
+			types = []byte{msgItems, msgMoreItems}
+			continue
+		}
+
+		buf := []byte{msgReply}
+		for range n {
+			r, err := s.readSpan(msg)
+			if err != nil {
+				return Diff[K, V]{}, err
+			}
+			count, err := msg.uvarint()
+			if err != nil {
+				return Diff[K, V]{}, err
+			}
+			hash, err := msg.uint64()
+			if err != nil {
+				return Diff[K, V]{}, err
+			}
+
+			switch local := s.count(r); {
+			case uint64(local) == count && s.hash(r) == hash:
+				buf = append(buf, replySame)
+			case local <= s.threshold || count == 0:
+				buf = append(buf, replyItems)
+				buf = s.appendEntries(buf, r)
+			default:
+				buf = append(buf, replySplit)
+				mid := s.tree.Select(s.rank(r.lo, 0) + local/2)
+				buf = s.appendKey(buf, mid.Key())
+			}
+		}
+		if len(msg.buf) != 0 {
+			return Diff[K, V]{}, ErrProtocol
+		}
+		if err := writeFrame(rw, buf); err != nil {
+			return Diff[K, V]{}, err
+		}
+	}
+}
+
+type session[K cmp.Ordered, V any] struct {
+	*Peer[K, V]
+	tree      *aa.Tree[K, V]
+	threshold int
+}
+
+func (p *Peer[K, V]) newSession(tree *aa.Tree[K, V]) *session[K, V] {
+	s := &session[K, V]{Peer: p, tree: tree, threshold: p.Threshold}
+	if s.threshold <= 0 {
+		s.threshold = 16
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.hasher == nil {
+		size := p.HashCache
+		if size <= 0 {
+			size = 1 << 16
+		}
+		p.hasher = aa.NewHasher(func(key K, value V) uint64 {
+			h := fnv.New64a()
+			h.Write(appendBytes(nil, p.Key.Append(nil, key)))
+			h.Write(p.Value.Append(nil, value))
+			return h.Sum64()
+		}, size)
+	}
+	return s
+}
+
+// A bound is a key, or the lack of one (unbounded).
+type bound[K cmp.Ordered] struct {
+	key K
+	ok  bool
+}
+
+// A span is the range of keys greater-than or equal-to lo, and less than hi.
+type span[K cmp.Ordered] struct {
+	lo, hi bound[K]
+}
+
+func (r span[K]) contains(key K) bool {
+	return (!r.lo.ok || !cmp.Less(key, r.lo.key)) &&
+		(!r.hi.ok || cmp.Less(key, r.hi.key))
+}
+
+type entry[K cmp.Ordered, V any] struct {
+	key K
+	val V
+	enc []byte
+}
+
+// rank returns the rank of b, or def if b is unbounded.
+func (s *session[K, V]) rank(b bound[K], def int) int {
+	if b.ok {
+		return s.tree.Rank(b.key)
+	}
+	return def
+}
+
+func (s *session[K, V]) count(r span[K]) int {
+	return s.rank(r.hi, s.tree.Len()) - s.rank(r.lo, 0)
+}
+
+func (s *session[K, V]) hash(r span[K]) uint64 {
+	// Sessions share the hasher of their peer.
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	hi := s.hasher.Hash(s.tree)
+	if r.hi.ok {
+		hi = s.hasher.HashLess(s.tree, r.hi.key)
+	}
+	var lo uint64
+	if r.lo.ok {
+		lo = s.hasher.HashLess(s.tree, r.lo.key)
+	}
+	return hi - lo
+}
+
+func (s *session[K, V]) entries(r span[K]) iter.Seq2[K, V] {
+	seq := s.tree.Ascend()
+	if r.lo.ok {
+		seq = s.tree.AscendCeil(r.lo.key)
+	}
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if r.hi.ok && !cmp.Less(k, r.hi.key) {
+				return
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// diff adds to d the differences between our entries in r, and theirs.
+func (s *session[K, V]) diff(d *Diff[K, V], r span[K], theirs []entry[K, V]) {
+	var remote *aa.Tree[K, []byte]
+	for _, e := range theirs {
+		remote = remote.Put(e.key, e.enc)
+	}
+	for k, v := range s.entries(r) {
+		enc, ok := remote.Get(k)
+		if !ok || !bytes.Equal(enc, s.Value.Append(nil, v)) {
+			d.Local = d.Local.Put(k, v)
+		}
+	}
+	for _, e := range theirs {
+		v, ok := s.tree.Get(e.key)
+		if !ok || !bytes.Equal(e.enc, s.Value.Append(nil, v)) {
+			d.Remote = d.Remote.Put(e.key, e.val)
+		}
+	}
+}
+
+func (s *session[K, V]) appendKey(buf []byte, key K) []byte {
+	return appendBytes(buf, s.Key.Append(nil, key))
+}
+
+func (s *session[K, V]) appendBound(buf []byte, b bound[K]) []byte {
+	if !b.ok {
+		return append(buf, 0)
+	}
+	return s.appendKey(append(buf, 1), b.key)
+}
+
+func (s *session[K, V]) appendSpan(buf []byte, r span[K]) []byte {
+	return s.appendBound(s.appendBound(buf, r.lo), r.hi)
+}
+
+// writeItems sends our entries in spans as an items message.
+// Large messages are split into several frames,
+// splitting spans between entries as needed.
+func (s *session[K, V]) writeItems(w io.Writer, spans []span[K]) error {
+	var frame []byte // spans, and their entries
+	var count int    // of spans in frame
+	flush := func(typ byte) error {
+		buf := binary.AppendUvarint([]byte{typ}, uint64(count))
+		buf = append(buf, frame...)
+		frame, count = frame[:0], 0
+		return writeFrame(w, buf)
+	}
+	add := func(r span[K], n int, entries []byte) {
+		frame = s.appendSpan(frame, r)
+		frame = binary.AppendUvarint(frame, uint64(n))
+		frame = append(frame, entries...)
+		count++
+	}
+
+	var entries []byte
+	for _, r := range spans {
+		if len(frame) >= itemsFrame {
+			if err := flush(msgMoreItems); err != nil {
+				return err
+			}
+		}
+		lo, n := r.lo, 0
+		entries = entries[:0]
+		for k, v := range s.entries(r) {
+			if n > 0 && len(frame)+len(entries) >= itemsFrame {
+				// Split the span before k.
+				hi := bound[K]{k, true}
+				add(span[K]{lo, hi}, n, entries)
+				if err := flush(msgMoreItems); err != nil {
+					return err
+				}
+				lo, n, entries = hi, 0, entries[:0]
+			}
+			entries = s.appendKey(entries, k)
+			entries = appendBytes(entries, s.Value.Append(nil, v))
+			n++
+		}
+		add(span[K]{lo, r.hi}, n, entries)
+	}
+	return flush(msgItems)
+}
+
+func (s *session[K, V]) appendEntries(buf []byte, r span[K]) []byte {
+	buf = binary.AppendUvarint(buf, uint64(s.count(r)))
+	for k, v := range s.entries(r) {
+		buf = s.appendKey(buf, k)
+		buf = appendBytes(buf, s.Value.Append(nil, v))
+	}
+	return buf
+}
+
+func (s *session[K, V]) readKey(msg *message) (K, error) {
+	b, err := msg.bytes()
+	if err != nil {
+		var zero K
+		return zero, err
+	}
+	return s.Key.Decode(b)
+}
+
+func (s *session[K, V]) readBound(msg *message) (bound[K], error) {
+	switch ok, err := msg.byte(); {
+	case err != nil:
+		return bound[K]{}, err
+	case ok == 0:
+		return bound[K]{}, nil
+	case ok == 1:
+		key, err := s.readKey(msg)
+		return bound[K]{key, true}, err
+	default:
+		return bound[K]{}, ErrProtocol
+	}
+}
+
+func (s *session[K, V]) readSpan(msg *message) (r span[K], err error) {
+	r.lo, err = s.readBound(msg)
+	if err == nil {
+		r.hi, err = s.readBound(msg)
+	}
+	if err == nil && r.lo.ok && r.hi.ok && !cmp.Less(r.lo.key, r.hi.key) {
+		err = ErrProtocol
+	}
+	return r, err
+}
+
+func (s *session[K, V]) readEntries(msg *message) ([]entry[K, V], error) {
+	n, err := msg.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(msg.buf)) {
+		return nil, ErrProtocol
+	}
+	entries := make([]entry[K, V], n)
+	for i := range entries {
+		e := &entries[i]
+		if e.key, err = s.readKey(msg); err != nil {
+			return nil, err
+		}
+		if e.enc, err = msg.bytes(); err != nil {
+			return nil, err
+		}
+		if e.val, err = s.Value.Decode(e.enc); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// A message is a frame being decoded.
+type message struct {
+	typ byte
+	buf []byte
+}
+
+func (m *message) byte() (byte, error) {
+	if len(m.buf) < 1 {
+		return 0, ErrProtocol
+	}
+	b := m.buf[0]
+	m.buf = m.buf[1:]
+	return b, nil
+}
+
+func (m *message) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(m.buf)
+	if n <= 0 {
+		return 0, ErrProtocol
+	}
+	m.buf = m.buf[n:]
+	return v, nil
+}
+
+func (m *message) uint64() (uint64, error) {
+	if len(m.buf) < 8 {
+		return 0, ErrProtocol
+	}
+	v := binary.BigEndian.Uint64(m.buf)
+	m.buf = m.buf[8:]
+	return v, nil
+}
+
+func (m *message) bytes() ([]byte, error) {
+	n, err := m.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(m.buf)) {
+		return nil, ErrProtocol
+	}
+	b := m.buf[:n:n]
+	m.buf = m.buf[n:]
+	return b, nil
+}
+
+// Frames are a 4 byte big-endian length, followed by the payload.
+// The first byte of the payload is the message type.
+
+func writeFrame(w io.Writer, payload []byte) error {
+	if len(payload) > maxFrame {
+		return ErrFrameTooLarge
+	}
+	buf := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), uint32(len(payload)))
+	_, err := w.Write(append(buf, payload...))
+	return err
+}
+
+func readFrame(r io.Reader, types ...byte) (*message, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n < 1 || n > maxFrame {
+		return nil, ErrProtocol
+	}
+	// Don't trust the header to size the buffer:
+	// let it grow as the payload arrives.
+	buf, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < int(n) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	for _, t := range types {
+		if buf[0] == t {
+			return &message{typ: t, buf: buf[1:]}, nil
+		}
+	}
+	return nil, ErrProtocol
+}
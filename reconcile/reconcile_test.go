@@ -0,0 +1,208 @@
+package reconcile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ncruces/aa"
+)
+
+var peer = Peer[int, string]{
+	Key: Codec[int]{
+		Append: func(buf []byte, v int) []byte {
+			return binary.AppendVarint(buf, int64(v))
+		},
+		Decode: func(buf []byte) (int, error) {
+			v, n := binary.Varint(buf)
+			if n != len(buf) {
+				return 0, errors.New("bad key")
+			}
+			return int(v), nil
+		},
+	},
+	Value: Codec[string]{
+		Append: func(buf []byte, v string) []byte {
+			return append(buf, v...)
+		},
+		Decode: func(buf []byte) (string, error) {
+			return string(buf), nil
+		},
+	},
+	Threshold: 4,
+}
+
+func reconcile(t *testing.T, t1, t2 *aa.Tree[int, string]) (d1, d2 Diff[int, string]) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		d2, err = peer.Respond(c2, t2)
+		errc <- err
+	}()
+
+	d1, err := peer.Initiate(c1, t1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	return d1, d2
+}
+
+func TestReconcile(t *testing.T) {
+	var t1, t2 *aa.Tree[int, string]
+	r := rand.New(rand.NewSource(42))
+	for _, i := range r.Perm(1000) {
+		s := strconv.Itoa(i)
+		t1 = t1.Put(i, s)
+		t2 = t2.Put(i, s)
+	}
+
+	t1 = t1.Put(-1, "local").Delete(100).Put(500, "changed")
+	t2 = t2.Put(2000, "remote").Delete(700)
+
+	d1, d2 := reconcile(t, t1, t2)
+
+	want1 := aa.MakeMap(map[int]string{-1: "local", 500: "changed", 700: "700"})
+	want2 := aa.MakeMap(map[int]string{2000: "remote", 500: "500", 100: "100"})
+	if !aa.Equal(d1.Local, want1) || !aa.Equal(d2.Remote, want1) {
+		t.Error(d1.Local.Collect(), d2.Remote.Collect())
+	}
+	if !aa.Equal(d1.Remote, want2) || !aa.Equal(d2.Local, want2) {
+		t.Error(d1.Remote.Collect(), d2.Local.Collect())
+	}
+
+	// Merging converges.
+	m1 := aa.Union(t1, d1.Remote)
+	m2 := aa.Union(d2.Remote, t2)
+	if !aa.Equal(m1, m2) {
+		t.Error()
+	}
+
+	// Later sessions reuse cached hashes.
+	d1, d2 = reconcile(t, m1, m2)
+	if d1.Local != nil || d1.Remote != nil || d2.Local != nil || d2.Remote != nil {
+		t.Error()
+	}
+}
+
+func TestReconcile_empty(t *testing.T) {
+	t1 := aa.MakeMap(map[int]string{1: "one", 2: "two"})
+
+	d1, d2 := reconcile(t, nil, t1)
+	if d1.Local != nil || !aa.Equal(d1.Remote, t1) {
+		t.Error()
+	}
+	if d2.Remote != nil || !aa.Equal(d2.Local, t1) {
+		t.Error()
+	}
+
+	d1, d2 = reconcile(t, t1, t1)
+	if d1.Local != nil || d1.Remote != nil || d2.Local != nil || d2.Remote != nil {
+		t.Error()
+	}
+}
+
+func TestReconcile_error(t *testing.T) {
+	c1, c2 := net.Pipe()
+	go func() {
+		writeFrame(c2, []byte{msgItems, 1, 2})
+		c2.Close()
+	}()
+	if _, err := peer.Respond(c1, nil); err != ErrProtocol {
+		t.Error(err)
+	}
+}
+
+func TestReconcile_frameLength(t *testing.T) {
+	// A huge length header must not allocate
+	// before the payload arrives.
+	hdr := binary.BigEndian.AppendUint32(nil, maxFrame)
+	r := bytes.NewReader(append(hdr, msgItems))
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readFrame(r, msgItems)
+	runtime.ReadMemStats(&after)
+
+	if err != io.ErrUnexpectedEOF {
+		t.Error(err)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes", n)
+	}
+}
+
+// frameCounter counts the frames written of each message type.
+type frameCounter struct {
+	io.ReadWriter
+	frames map[byte]int
+}
+
+func (c *frameCounter) Write(p []byte) (int, error) {
+	if len(p) > 4 {
+		c.frames[p[4]]++
+	}
+	return c.ReadWriter.Write(p)
+}
+
+func TestReconcile_largeItems(t *testing.T) {
+	value := strings.Repeat("x", 1000)
+	var t1 *aa.Tree[int, string]
+	for i := range 3000 {
+		t1 = t1.Put(i, value)
+	}
+	t2 := aa.MakeMap(map[int]string{1500: "remote"})
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	errc := make(chan error, 1)
+	var d2 Diff[int, string]
+	go func() {
+		var err error
+		d2, err = peer.Respond(c2, t2)
+		errc <- err
+	}()
+
+	w := &frameCounter{c1, map[byte]int{}}
+	d1, err := peer.Initiate(w, t1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	if w.frames[msgItems] != 1 || w.frames[msgMoreItems] < 2 {
+		t.Error(w.frames)
+	}
+	if !aa.Equal(d2.Remote, t1) || !aa.Equal(d2.Local, t2) {
+		t.Error(d2.Remote.Len(), d2.Local.Collect())
+	}
+	if !aa.Equal(d1.Local, t1) || !aa.Equal(d1.Remote, t2) {
+		t.Error(d1.Local.Len(), d1.Remote.Collect())
+	}
+}
+
+func TestReconcile_frameTooLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	if err := writeFrame(io.Discard, make([]byte, maxFrame+1)); err != ErrFrameTooLarge {
+		t.Error(err)
+	}
+}
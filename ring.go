@@ -0,0 +1,152 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Ring is an immutable consistent-hashing ring.
+//
+// Each member is placed on the ring at several points (virtual nodes),
+// and owns the hashes from the previous point, exclusive,
+// to each of its points, inclusive, wrapping around at the end.
+type Ring[M cmp.Ordered] struct {
+	points  *Tree[uint64, *Tree[M, struct{}]] // point → members placed there
+	members *Tree[M, int]
+	hash    func(member M, replica int) uint64
+}
+
+// NewRing creates an empty ring that places
+// replica i of a member at point hash(member, i).
+//
+// If points collide, the least member placed there owns the point.
+func NewRing[M cmp.Ordered](hash func(member M, replica int) uint64) Ring[M] {
+	return Ring[M]{hash: hash}
+}
+
+// Len returns the number of members of this ring.
+func (r Ring[M]) Len() int {
+	return r.members.Len()
+}
+
+// Members returns an ascending iterator for the members of this ring,
+// and their number of replicas.
+func (r Ring[M]) Members() iter.Seq2[M, int] {
+	return r.members.Ascend()
+}
+
+// Add returns a modified ring with member placed at replicas points.
+// If member already exists, it is replaced.
+func (r Ring[M]) Add(member M, replicas int) Ring[M] {
+	r = r.Remove(member)
+	r.members = r.members.Put(member, replicas)
+	for i := range replicas {
+		r.points = r.points.Patch(r.hash(member, i), func(node *Tree[uint64, *Tree[M, struct{}]]) (*Tree[M, struct{}], bool) {
+			var owners *Tree[M, struct{}]
+			if node != nil {
+				owners = node.value
+			}
+			return owners.Add(member), true
+		})
+	}
+	return r
+}
+
+// Remove returns a (possibly) modified ring with member removed from it.
+func (r Ring[M]) Remove(member M) Ring[M] {
+	members, replicas, found := r.members.LoadAndDelete(member)
+	if !found {
+		return r
+	}
+	r.members = members
+	for i := range replicas {
+		point := r.hash(member, i)
+		node := r.points.lookup(point)
+		if node == nil {
+			continue // Replicas collided.
+		}
+		if owners := node.value.Delete(member); owners == nil {
+			r.points = r.points.Delete(point)
+		} else {
+			r.points = r.points.Put(point, owners)
+		}
+	}
+	return r
+}
+
+// Locate returns the member that owns hash:
+// the member at the first point clockwise from hash;
+// ok is false if this ring is empty.
+func (r Ring[M]) Locate(hash uint64) (member M, ok bool) {
+	node := r.points.Ceil(hash)
+	if node == nil {
+		node = r.points.Min() // Wrap around.
+	}
+	if node == nil {
+		return
+	}
+	return node.value.Min().key, true
+}
+
+// LocateN returns up to n distinct members for hash,
+// walking clockwise from hash, and wrapping around at the end.
+// The first member is the one that owns hash;
+// members placed at the same point follow in ascending order.
+//
+// If n is at least Len, LocateN returns every member of this ring,
+// even those whose points all collided.
+func (r Ring[M]) LocateN(hash uint64, n int) []M {
+	n = min(n, r.members.Len())
+	if n <= 0 {
+		return nil
+	}
+
+	res := make([]M, 0, n)
+	walk := func(seq iter.Seq2[uint64, *Tree[M, struct{}]]) {
+		for _, owners := range seq {
+			for m := range owners.Ascend() {
+				if len(res) == n {
+					return
+				}
+				if !slices.Contains(res, m) {
+					res = append(res, m)
+				}
+			}
+		}
+	}
+	walk(r.points.AscendCeil(hash))
+	walk(r.points.Ascend()) // Wrap around.
+	return res
+}
+
+// Ownership returns the fraction of the hash space owned by member.
+func (r Ring[M]) Ownership(member M) float64 {
+	replicas, found := r.members.Get(member)
+	if !found {
+		return 0
+	}
+	points := make([]uint64, replicas)
+	for i := range points {
+		points[i] = r.hash(member, i)
+	}
+	slices.Sort(points)
+	points = slices.Compact(points)
+
+	var owned float64
+	for _, point := range points {
+		if r.points.lookup(point).value.Min().key != member {
+			continue // Collided with a lesser member.
+		}
+		if r.points.Len() == 1 {
+			return 1
+		}
+		prev := r.points.Floor(point - 1)
+		if prev == nil || point == 0 {
+			prev = r.points.Max() // Wrap around.
+		}
+		// Arc lengths are computed modulo 2⁶⁴.
+		owned += float64(point - prev.key)
+	}
+	return owned / (1 << 64)
+}
@@ -0,0 +1,159 @@
+package aa
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func ringHash(member string, replica int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(member + "#" + strconv.Itoa(replica)))
+	// FNV clusters similar inputs; mix it.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return x
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(ringHash)
+	if _, ok := r.Locate(42); ok {
+		t.Error()
+	}
+	if m := r.LocateN(42, 3); m != nil {
+		t.Error(m)
+	}
+
+	r = r.Add("a", 100).Add("b", 100).Add("c", 100)
+	r.points.check()
+	if r.Len() != 3 || r.points.Len() != 300 {
+		t.Error(r.Len(), r.points.Len())
+	}
+
+	// Locate wraps around.
+	first, last := r.points.Min(), r.points.Max()
+	if m, _ := r.Locate(last.Key() + 1); m != first.Value().Min().Key() {
+		t.Error(m)
+	}
+	if m, _ := r.Locate(last.Key()); m != last.Value().Min().Key() {
+		t.Error(m)
+	}
+
+	for i := range 100 {
+		h := ringHash("key", i)
+		owners := r.LocateN(h, 5)
+		if len(owners) != 3 {
+			t.Fatal(owners)
+		}
+		if m, _ := r.Locate(h); m != owners[0] {
+			t.Fatal(m, owners)
+		}
+		slices.Sort(owners)
+		if !slices.Equal(owners, []string{"a", "b", "c"}) {
+			t.Fatal(owners)
+		}
+	}
+
+	var total float64
+	for m := range r.Members() {
+		f := r.Ownership(m)
+		if f < 0.2 || f > 0.5 {
+			t.Error(m, f)
+		}
+		total += f
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Error(total)
+	}
+	if f := r.Ownership("z"); f != 0 {
+		t.Error(f)
+	}
+
+	// Removing a member only moves its keys.
+	s := r.Remove("b").Remove("z")
+	if s.Len() != 2 || s.points.Len() != 200 {
+		t.Error(s.Len(), s.points.Len())
+	}
+	for i := range 100 {
+		h := ringHash("key", i)
+		before, _ := r.Locate(h)
+		after, _ := s.Locate(h)
+		if before != "b" && before != after {
+			t.Fatal(before, after)
+		}
+	}
+
+	if f := NewRing(ringHash).Add("x", 1).Ownership("x"); f != 1 {
+		t.Error(f)
+	}
+}
+
+func TestRing_collision(t *testing.T) {
+	r := NewRing(func(member string, replica int) uint64 {
+		return uint64(replica)
+	})
+	r = r.Add("b", 2).Add("a", 1)
+	if m, _ := r.Locate(0); m != "a" {
+		t.Error(m)
+	}
+	if m, _ := r.Locate(1); m != "b" {
+		t.Error(m)
+	}
+	if f := r.Ownership("a") + r.Ownership("b"); f != 1 {
+		t.Error(f)
+	}
+
+	// Removing a member hands collided points back,
+	// regardless of the order members were added.
+	h := func(member string, replica int) uint64 {
+		return map[string][]uint64{
+			"a": {0},
+			"b": {0, 100},
+			"c": {50},
+		}[member][replica]
+	}
+	r = NewRing(h).Add("a", 1).Add("b", 2).Add("c", 1).Remove("a")
+	s := NewRing(h).Add("b", 2).Add("c", 1)
+	r.points.check()
+	if !Equal(r.members, s.members) || r.points.Len() != s.points.Len() {
+		t.Error(r.points.Len(), s.points.Len())
+	}
+	for _, x := range []uint64{0, 1, 50, 51, 100, 101} {
+		m1, _ := r.Locate(x)
+		m2, _ := s.Locate(x)
+		if m1 != m2 {
+			t.Error(x, m1, m2)
+		}
+	}
+	if m, _ := r.Locate(0); m != "b" {
+		t.Error(m)
+	}
+	if f := r.Ownership("b"); f < 0.99 {
+		t.Error(f)
+	}
+
+	// A single point is owned by the least member placed there.
+	r = NewRing(func(string, int) uint64 { return 42 }).Add("a", 1).Add("b", 1)
+	if fa, fb := r.Ownership("a"), r.Ownership("b"); fa != 1 || fb != 0 {
+		t.Error(fa, fb)
+	}
+	if got := r.LocateN(0, 2); !slices.Equal(got, []string{"a", "b"}) {
+		t.Error(got)
+	}
+	if got := r.LocateN(0, 1); !slices.Equal(got, []string{"a"}) {
+		t.Error(got)
+	}
+
+	// Replicas of a member may collide too.
+	r = NewRing(func(string, int) uint64 { return 42 }).Add("a", 3).Add("b", 2)
+	if f := r.Ownership("a"); f != 1 {
+		t.Error(f)
+	}
+	if r = r.Remove("a"); r.points.Len() != 1 || r.Ownership("b") != 1 {
+		t.Error(r.points.Len())
+	}
+}
@@ -0,0 +1,79 @@
+package aa
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// Sample picks a node from this tree uniformly at random,
+// using r as the source of randomness
+// (or the global source, if r is nil).
+//
+// Note: sampling the empty tree (nil) returns nil.
+func (tree *Tree[K, V]) Sample(r *rand.Rand) *Tree[K, V] {
+	n := tree.Len()
+	if n == 0 {
+		return nil
+	}
+	return tree.Select(intN(r, n))
+}
+
+// SampleN picks n distinct nodes from this tree uniformly at random,
+// using r as the source of randomness
+// (or the global source, if r is nil),
+// and returns them in ascending key order.
+//
+// If n is greater than the number of nodes in this tree,
+// all nodes are returned.
+func (tree *Tree[K, V]) SampleN(r *rand.Rand, n int) []*Tree[K, V] {
+	l := tree.Len()
+	n = min(n, l)
+	if n <= 0 {
+		return nil
+	}
+
+	// Robert Floyd's algorithm.
+	// https://doi.org/10.1145/30401.315746
+	picked := make(map[int]struct{}, n)
+	for j := l - n; j < l; j++ {
+		i := intN(r, j+1)
+		if _, ok := picked[i]; ok {
+			i = j
+		}
+		picked[i] = struct{}{}
+	}
+
+	idx := make([]int, 0, n)
+	for i := range picked {
+		idx = append(idx, i)
+	}
+	slices.Sort(idx)
+
+	res := make([]*Tree[K, V], n)
+	for j, i := range idx {
+		res[j] = tree.Select(i)
+	}
+	return res
+}
+
+// SampleRange picks a node from this tree uniformly at random,
+// among those with keys greater-than or equal-to lo, and less than hi,
+// using r as the source of randomness
+// (or the global source, if r is nil).
+//
+// Note: SampleRange returns nil if no such key exists in this tree.
+func (tree *Tree[K, V]) SampleRange(r *rand.Rand, lo, hi K) *Tree[K, V] {
+	i := tree.Rank(lo)
+	j := tree.Rank(hi)
+	if i >= j {
+		return nil
+	}
+	return tree.Select(i + intN(r, j-i))
+}
+
+func intN(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.IntN(n)
+	}
+	return r.IntN(n)
+}
@@ -0,0 +1,89 @@
+package aa
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestTree_Sample(t *testing.T) {
+	var tt *Tree[int, struct{}]
+	if tt.Sample(nil) != nil {
+		t.Error()
+	}
+
+	tt = MakeSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	r := rand.New(rand.NewPCG(1, 2))
+
+	var counts [10]int
+	for range 10000 {
+		counts[tt.Sample(r).Key()]++
+	}
+	for i, c := range counts {
+		if c < 800 || c > 1200 {
+			t.Errorf("%d: %d", i, c)
+		}
+	}
+
+	// Reproducible.
+	r1 := rand.New(rand.NewPCG(3, 4))
+	r2 := rand.New(rand.NewPCG(3, 4))
+	for range 100 {
+		if tt.Sample(r1) != tt.Sample(r2) {
+			t.Fatal()
+		}
+	}
+
+	if tt.Sample(nil) == nil {
+		t.Error()
+	}
+}
+
+func TestTree_SampleN(t *testing.T) {
+	tt := MakeSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	r := rand.New(rand.NewPCG(1, 2))
+
+	if s := tt.SampleN(r, 0); s != nil {
+		t.Error(s)
+	}
+	if s := tt.SampleN(r, 20); len(s) != 10 {
+		t.Error(len(s))
+	}
+
+	var counts [10]int
+	for range 10000 {
+		s := tt.SampleN(r, 3)
+		if len(s) != 3 {
+			t.Fatal(len(s))
+		}
+		for i := 1; i < len(s); i++ {
+			if s[i-1].Key() >= s[i].Key() {
+				t.Fatal(s[i-1].Key(), s[i].Key())
+			}
+		}
+		for _, n := range s {
+			counts[n.Key()]++
+		}
+	}
+	for i, c := range counts {
+		if c < 2700 || c > 3300 {
+			t.Errorf("%d: %d", i, c)
+		}
+	}
+}
+
+func TestTree_SampleRange(t *testing.T) {
+	tt := MakeSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	r := rand.New(rand.NewPCG(1, 2))
+
+	if n := tt.SampleRange(r, 5, 5); n != nil {
+		t.Error(n)
+	}
+	if n := tt.SampleRange(r, 20, 30); n != nil {
+		t.Error(n)
+	}
+	for range 1000 {
+		if k := tt.SampleRange(r, 3, 6).Key(); k < 3 || k >= 6 {
+			t.Fatal(k)
+		}
+	}
+}
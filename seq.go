@@ -0,0 +1,121 @@
+package aa
+
+import (
+	"cmp"
+	"iter"
+)
+
+// UnionSeq returns an ascending iterator for the set union of two trees,
+// last value wins, without building it.
+//
+// Subtrees of either tree that fall between keys of the other,
+// or that are shared by both trees, are iterated wholesale.
+func UnionSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { unionSeq(t1, t2, nil, nil, yield) }
+}
+
+func unionSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V], lo, hi *K, yield func(K, V) bool) bool {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == nil || t1 == t2:
+		return t2.ascend(yield)
+	case t2 == nil:
+		return t1.ascendBetween(lo, hi, yield)
+	}
+	return unionSeq(t1, t2.left, lo, &t2.key, yield) &&
+		yield(t2.key, t2.value) &&
+		unionSeq(t1, t2.right, &t2.key, hi, yield)
+}
+
+// IntersectionSeq returns an ascending iterator for the set intersection of two trees,
+// first value wins, without building it.
+//
+// Subtrees of either tree that fall between keys of the other are skipped,
+// and subtrees shared by both trees are iterated wholesale.
+func IntersectionSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { intersectionSeq(t1, t2, nil, nil, yield) }
+}
+
+func intersectionSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V], lo, hi *K, yield func(K, V) bool) bool {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == nil || t2 == nil:
+		return true
+	case t1 == t2:
+		return t2.ascend(yield)
+	}
+	if !intersectionSeq(t1, t2.left, lo, &t2.key, yield) {
+		return false
+	}
+	if node := t1.lookup(t2.key); node != nil && !yield(node.key, node.value) {
+		return false
+	}
+	return intersectionSeq(t1, t2.right, &t2.key, hi, yield)
+}
+
+// DifferenceSeq returns an ascending iterator for the set difference of two trees,
+// without building it.
+//
+// Subtrees of either tree that fall between keys of the other are
+// iterated wholesale, and subtrees shared by both trees are skipped.
+func DifferenceSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { differenceSeq(t1, t2, nil, nil, yield) }
+}
+
+func differenceSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V], lo, hi *K, yield func(K, V) bool) bool {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == nil || t1 == t2:
+		return true
+	case t2 == nil:
+		return t1.ascendBetween(lo, hi, yield)
+	}
+	return differenceSeq(t1, t2.left, lo, &t2.key, yield) &&
+		differenceSeq(t1, t2.right, &t2.key, hi, yield)
+}
+
+// SymmetricDifferenceSeq returns an ascending iterator for the
+// set symmetric difference of two trees, without building it.
+//
+// Subtrees of either tree that fall between keys of the other are
+// iterated wholesale, and subtrees shared by both trees are skipped.
+func SymmetricDifferenceSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { symmetricDifferenceSeq(t1, t2, nil, nil, yield) }
+}
+
+func symmetricDifferenceSeq[K cmp.Ordered, V any](t1, t2 *Tree[K, V], lo, hi *K, yield func(K, V) bool) bool {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == t2:
+		return true
+	case t1 == nil:
+		return t2.ascend(yield)
+	case t2 == nil:
+		return t1.ascendBetween(lo, hi, yield)
+	}
+	if !symmetricDifferenceSeq(t1, t2.left, lo, &t2.key, yield) {
+		return false
+	}
+	if t1.lookup(t2.key) == nil && !yield(t2.key, t2.value) {
+		return false
+	}
+	return symmetricDifferenceSeq(t1, t2.right, &t2.key, hi, yield)
+}
+
+// ascendBetween iterates over the keys in this tree
+// between lo and hi, exclusive (either can be nil, for no bound).
+func (tree *Tree[K, V]) ascendBetween(lo, hi *K, yield func(K, V) bool) bool {
+	for tree != nil {
+		switch {
+		case lo != nil && !cmp.Less(*lo, tree.key):
+			tree = tree.right
+		case hi != nil && !cmp.Less(tree.key, *hi):
+			tree = tree.left
+		default:
+			return tree.left.ascendBetween(lo, nil, yield) &&
+				yield(tree.key, tree.value) &&
+				tree.right.ascendBetween(nil, hi, yield)
+		}
+	}
+	return true
+}
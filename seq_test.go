@@ -0,0 +1,70 @@
+package aa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	check := func(name string, want *Tree[int, int], seq func(yield func(int, int) bool)) {
+		t.Helper()
+		var got *Tree[int, int]
+		last := -1
+		for k, v := range seq {
+			if k <= last {
+				t.Fatalf("%s: %d ≤ %d", name, k, last)
+			}
+			last = k
+			got = got.Put(k, v)
+		}
+		if !Equal(got, want) {
+			t.Fatalf("%s: %v ≠ %v", name, got.Collect(), want.Collect())
+		}
+	}
+
+	for range 100 {
+		var t1, t2 *Tree[int, int]
+		for range r.Intn(100) {
+			t1 = t1.Put(r.Intn(100), 1)
+		}
+		t2 = t1
+		for range r.Intn(100) {
+			t2 = t2.Put(r.Intn(200), 2)
+		}
+		for range r.Intn(100) {
+			t2 = t2.Delete(r.Intn(100))
+		}
+
+		check("union", Union(t1, t2), UnionSeq(t1, t2))
+		check("intersection", Intersection(t1, t2), IntersectionSeq(t1, t2))
+		check("difference", Difference(t1, t2), DifferenceSeq(t1, t2))
+		check("difference", Difference(t2, t1), DifferenceSeq(t2, t1))
+		check("symmetric difference", SymmetricDifference(t1, t2), SymmetricDifferenceSeq(t1, t2))
+	}
+}
+
+func TestSeq_break(t *testing.T) {
+	t1 := MakeSet(0, 2, 4, 6, 8)
+	t2 := MakeSet(1, 2, 3, 4, 5, 6)
+
+	seqs := []func(yield func(int, struct{}) bool){
+		UnionSeq(t1, t2),
+		IntersectionSeq(t1, t2),
+		DifferenceSeq(t1, t2),
+		DifferenceSeq(t2, t1),
+		SymmetricDifferenceSeq(t1, t2),
+	}
+	for _, seq := range seqs {
+		for i := range 10 {
+			n := 0
+			for range seq {
+				if n == i {
+					break
+				}
+				n++
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package aa
 
-import "cmp"
+import (
+	"cmp"
+	"iter"
+)
 
 // Union returns the set union of two trees,
 // last value wins.
@@ -35,6 +38,39 @@ func Intersection[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) *Tree[K, V] {
 	return join(left, node, right)
 }
 
+// UnionFunc returns the set union of two trees,
+// calling merge to combine the values of keys in both.
+func UnionFunc[K cmp.Ordered, V any](t1, t2 *Tree[K, V], merge func(key K, v1, v2 V) V) *Tree[K, V] {
+	switch {
+	case t1 == nil:
+		return t2
+	case t2 == nil:
+		return t1
+	}
+	left, node, right := t1.Split(t2.key)
+	left = UnionFunc(left, t2.left, merge)
+	right = UnionFunc(right, t2.right, merge)
+	if node == nil {
+		return join(left, t2, right)
+	}
+	return join(left, &Tree[K, V]{key: t2.key, value: merge(t2.key, node.value, t2.value)}, right)
+}
+
+// IntersectionFunc returns the set intersection of two trees,
+// calling merge to combine their values.
+func IntersectionFunc[K cmp.Ordered, V any](t1, t2 *Tree[K, V], merge func(key K, v1, v2 V) V) *Tree[K, V] {
+	if t1 == nil || t2 == nil {
+		return nil
+	}
+	left, node, right := t1.Split(t2.key)
+	left = IntersectionFunc(left, t2.left, merge)
+	right = IntersectionFunc(right, t2.right, merge)
+	if node == nil {
+		return join2(left, right)
+	}
+	return join(left, &Tree[K, V]{key: t2.key, value: merge(t2.key, node.value, t2.value)}, right)
+}
+
 // Difference returns the set difference of two trees.
 func Difference[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) *Tree[K, V] {
 	switch {
@@ -67,3 +103,159 @@ func SymmetricDifference[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) *Tree[K, V] {
 	}
 	return join2(left, right)
 }
+
+// Restrict returns a tree with the nodes of tree whose keys are in keys.
+//
+// Unlike Intersection, the value types of the trees can differ;
+// keys can be, for example, a Set's tree.
+func Restrict[K cmp.Ordered, V, W any](tree *Tree[K, V], keys *Tree[K, W]) *Tree[K, V] {
+	if tree == nil || keys == nil {
+		return nil
+	}
+	left, node, right := tree.Split(keys.key)
+	left = Restrict(left, keys.left)
+	right = Restrict(right, keys.right)
+	if node == nil {
+		return join2(left, right)
+	}
+	return join(left, node, right)
+}
+
+// Without returns a tree with the nodes of tree whose keys are not in keys.
+//
+// Unlike Difference, the value types of the trees can differ;
+// keys can be, for example, a Set's tree.
+func Without[K cmp.Ordered, V, W any](tree *Tree[K, V], keys *Tree[K, W]) *Tree[K, V] {
+	if tree == nil || keys == nil {
+		return tree
+	}
+	left, _, right := tree.Split(keys.key)
+	left = Without(left, keys.left)
+	right = Without(right, keys.right)
+	return join2(left, right)
+}
+
+// Set is an immutable set of keys.
+//
+// The zero value for Set is the empty set.
+//
+// Set wraps a *Tree[K, struct{}]; since struct{} takes no space,
+// each node holds just two pointers, a key and a balance word:
+// 4 machine words for uint64 keys on 64-bit platforms.
+type Set[K cmp.Ordered] struct {
+	tree *Tree[K, struct{}]
+}
+
+// SetOf builds a set from a list of keys.
+func SetOf[K cmp.Ordered](keys ...K) Set[K] {
+	return Set[K]{MakeSet(keys...)}
+}
+
+// Tree returns the tree that backs this set.
+func (s Set[K]) Tree() *Tree[K, struct{}] {
+	return s.tree
+}
+
+// Len returns the number of keys in this set.
+func (s Set[K]) Len() int {
+	return s.tree.Len()
+}
+
+// Contains reports whether key is in this set.
+func (s Set[K]) Contains(key K) bool {
+	return s.tree.Has(key)
+}
+
+// Insert returns a (possibly) modified set that contains key.
+func (s Set[K]) Insert(key K) Set[K] {
+	return Set[K]{s.tree.Add(key)}
+}
+
+// Remove returns a (possibly) modified set with key removed from it.
+func (s Set[K]) Remove(key K) Set[K] {
+	return Set[K]{s.tree.Delete(key)}
+}
+
+// All returns an ascending iterator for this set.
+func (s Set[K]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.tree.Ascend() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns a descending iterator for this set.
+func (s Set[K]) Backward() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.tree.Descend() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the least key in this set;
+// ok is false if this set is empty.
+func (s Set[K]) Min() (key K, ok bool) {
+	if n := s.tree.Min(); n != nil {
+		return n.key, true
+	}
+	return
+}
+
+// Max returns the greatest key in this set;
+// ok is false if this set is empty.
+func (s Set[K]) Max() (key K, ok bool) {
+	if n := s.tree.Max(); n != nil {
+		return n.key, true
+	}
+	return
+}
+
+// Select returns the key at index i of this set;
+// ok is false if i is out of range.
+func (s Set[K]) Select(i int) (key K, ok bool) {
+	if n := s.tree.Select(i); n != nil {
+		return n.key, true
+	}
+	return
+}
+
+// Rank returns the number of keys in this set less than key.
+func (s Set[K]) Rank(key K) int {
+	return s.tree.Rank(key)
+}
+
+// Union returns the set union of s and t.
+func (s Set[K]) Union(t Set[K]) Set[K] {
+	return Set[K]{Union(s.tree, t.tree)}
+}
+
+// Intersection returns the set intersection of s and t.
+func (s Set[K]) Intersection(t Set[K]) Set[K] {
+	return Set[K]{Intersection(s.tree, t.tree)}
+}
+
+// Difference returns the set difference of s and t.
+func (s Set[K]) Difference(t Set[K]) Set[K] {
+	return Set[K]{Difference(s.tree, t.tree)}
+}
+
+// SymmetricDifference returns the set symmetric difference of s and t.
+func (s Set[K]) SymmetricDifference(t Set[K]) Set[K] {
+	return Set[K]{SymmetricDifference(s.tree, t.tree)}
+}
+
+// Equal reports whether s and t contain the same keys.
+func (s Set[K]) Equal(t Set[K]) bool {
+	return Equal(s.tree, t.tree)
+}
+
+// Subset reports whether t contains every key in s.
+func (s Set[K]) Subset(t Set[K]) bool {
+	return Subset(s.tree, t.tree)
+}
@@ -1,8 +1,10 @@
 package aa
 
 import (
+	"maps"
 	"slices"
 	"testing"
+	"unsafe"
 )
 
 func TestUnion(t *testing.T) {
@@ -112,3 +114,92 @@ func TestSymmetricDifference(t *testing.T) {
 		t.Error(out)
 	}
 }
+
+func TestSet(t *testing.T) {
+	var s Set[uint64]
+	if s.Len() != 0 || s.Contains(0) {
+		t.Error()
+	}
+	if _, ok := s.Min(); ok {
+		t.Error()
+	}
+	if _, ok := s.Max(); ok {
+		t.Error()
+	}
+	if _, ok := s.Select(0); ok {
+		t.Error()
+	}
+
+	s = s.Insert(3).Insert(1).Insert(2).Insert(5).Remove(2)
+	s.Tree().check()
+
+	if !slices.Equal(slices.Collect(s.All()), []uint64{1, 3, 5}) {
+		t.Error(slices.Collect(s.All()))
+	}
+	if !slices.Equal(slices.Collect(s.Backward()), []uint64{5, 3, 1}) {
+		t.Error(slices.Collect(s.Backward()))
+	}
+	if k, ok := s.Min(); !ok || k != 1 {
+		t.Error(k)
+	}
+	if k, ok := s.Max(); !ok || k != 5 {
+		t.Error(k)
+	}
+	if k, ok := s.Select(1); !ok || k != 3 {
+		t.Error(k)
+	}
+	if i := s.Rank(4); i != 2 {
+		t.Error(i)
+	}
+
+	o := SetOf[uint64](0, 3, 4)
+	if u := s.Union(o); !u.Equal(SetOf[uint64](0, 1, 3, 4, 5)) {
+		t.Error(slices.Collect(u.All()))
+	}
+	if i := s.Intersection(o); !i.Equal(SetOf[uint64](3)) || !i.Subset(s) || !i.Subset(o) {
+		t.Error(slices.Collect(i.All()))
+	}
+	if d := s.Difference(o); !d.Equal(SetOf[uint64](1, 5)) {
+		t.Error(slices.Collect(d.All()))
+	}
+	if d := s.SymmetricDifference(o); !d.Equal(SetOf[uint64](0, 1, 4, 5)) {
+		t.Error(slices.Collect(d.All()))
+	}
+}
+
+func TestSet_size(t *testing.T) {
+	// Two pointers, a key and a balance word.
+	if n := unsafe.Sizeof(Tree[uint64, struct{}]{}); n != 3*unsafe.Sizeof(uintptr(0))+8 {
+		t.Error(n)
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	tt := MakeMap(map[int]string{1: "one", 2: "two", 3: "three", 5: "five"})
+	keys := SetOf(0, 2, 3, 4)
+
+	if a := Restrict(tt, keys.Tree()); !maps.Equal(a.Collect(), map[int]string{2: "two", 3: "three"}) {
+		t.Error(a.Collect())
+	}
+	if a := Restrict[int, string, struct{}](tt, nil); a != nil {
+		t.Error(a)
+	}
+	if a := Restrict[int, string](nil, keys.Tree()); a != nil {
+		t.Error(a)
+	}
+}
+
+func TestWithout(t *testing.T) {
+	tt := MakeMap(map[int]string{1: "one", 2: "two", 3: "three", 5: "five"})
+	keys := SetOf(0, 2, 3, 4)
+
+	if a := Without(tt, keys.Tree()); !maps.Equal(a.Collect(), map[int]string{1: "one", 5: "five"}) {
+		t.Error(a.Collect())
+	}
+	if a := Without[int, string, struct{}](tt, nil); a != tt {
+		t.Errorf("%p ≠ %p", a, tt)
+	}
+	if a := Without[int, string](nil, keys.Tree()); a != nil {
+		t.Error(a)
+	}
+}
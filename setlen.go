@@ -0,0 +1,74 @@
+package aa
+
+import "cmp"
+
+// IntersectionLen returns the number of keys in the
+// set intersection of two trees, without building it.
+//
+// Subtrees shared by both trees are counted wholesale.
+func IntersectionLen[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) int {
+	if t1.Len() < t2.Len() {
+		t1, t2 = t2, t1
+	}
+	return intersectionLen(t1, t2, nil, nil)
+}
+
+// UnionLen returns the number of keys in the
+// set union of two trees, without building it.
+func UnionLen[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) int {
+	return t1.Len() + t2.Len() - IntersectionLen(t1, t2)
+}
+
+// DifferenceLen returns the number of keys in the
+// set difference of two trees, without building it.
+func DifferenceLen[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) int {
+	return t1.Len() - IntersectionLen(t1, t2)
+}
+
+// Jaccard returns the Jaccard index of the keys of two trees,
+// the size of their intersection divided by the size of their union.
+//
+// Note: the Jaccard index of two empty trees is 1.
+func Jaccard[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) float64 {
+	i := IntersectionLen(t1, t2)
+	u := t1.Len() + t2.Len() - i
+	if u == 0 {
+		return 1
+	}
+	return float64(i) / float64(u)
+}
+
+// intersectionLen counts the keys of t2 in t1,
+// given that every key of t2 is between lo and hi.
+func intersectionLen[K cmp.Ordered, V any](t1, t2 *Tree[K, V], lo, hi *K) int {
+	t1 = t1.narrow(lo, hi)
+	switch {
+	case t1 == nil || t2 == nil:
+		return 0
+	case t1 == t2:
+		return t2.Len()
+	}
+	n := intersectionLen(t1, t2.left, lo, &t2.key) +
+		intersectionLen(t1, t2.right, &t2.key, hi)
+	if t1.Has(t2.key) {
+		n++
+	}
+	return n
+}
+
+// narrow finds the least subtree of this tree
+// that contains every key between lo and hi, exclusive
+// (either can be nil, for no bound).
+func (tree *Tree[K, V]) narrow(lo, hi *K) *Tree[K, V] {
+	for tree != nil {
+		switch {
+		case lo != nil && !cmp.Less(*lo, tree.key):
+			tree = tree.right
+		case hi != nil && !cmp.Less(tree.key, *hi):
+			tree = tree.left
+		default:
+			return tree
+		}
+	}
+	return nil
+}
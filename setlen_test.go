@@ -0,0 +1,68 @@
+package aa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIntersectionLen(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for range 100 {
+		var t1, t2 *Tree[int, int]
+		for range r.Intn(100) {
+			t1 = t1.Add(r.Intn(100))
+		}
+		t2 = t1
+		for range r.Intn(100) {
+			t2 = t2.Add(r.Intn(200))
+		}
+		for range r.Intn(100) {
+			t2 = t2.Delete(r.Intn(100))
+		}
+
+		i := Intersection(t1, t2).Len()
+		if n := IntersectionLen(t1, t2); n != i {
+			t.Fatalf("%d ≠ %d", n, i)
+		}
+		if n := UnionLen(t1, t2); n != Union(t1, t2).Len() {
+			t.Fatalf("%d ≠ %d", n, Union(t1, t2).Len())
+		}
+		if n := DifferenceLen(t1, t2); n != Difference(t1, t2).Len() {
+			t.Fatalf("%d ≠ %d", n, Difference(t1, t2).Len())
+		}
+		if n := DifferenceLen(t2, t1); n != Difference(t2, t1).Len() {
+			t.Fatalf("%d ≠ %d", n, Difference(t2, t1).Len())
+		}
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	if j := Jaccard[int, struct{}](nil, nil); j != 1 {
+		t.Error(j)
+	}
+	t1 := MakeSet(1, 2, 3)
+	if j := Jaccard(t1, t1); j != 1 {
+		t.Error(j)
+	}
+	if j := Jaccard(t1, MakeSet(2, 3, 4, 5)); j != 0.4 {
+		t.Error(j)
+	}
+	if j := Jaccard(t1, nil); j != 0 {
+		t.Error(j)
+	}
+}
+
+func BenchmarkIntersectionLen(b *testing.B) {
+	var tt *Tree[int, int]
+	for i := range 100000 {
+		tt = tt.Add(i)
+	}
+	t2 := tt.Delete(500).Add(-1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		IntersectionLen(tt, t2)
+	}
+}
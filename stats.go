@@ -41,3 +41,49 @@ func (tree *Tree[K, V]) Rank(key K) int {
 	}
 	return k
 }
+
+// Search finds the first node of this tree for which pred returns true,
+// and returns it and its index
+// (or nil and tree.Len(), if there is no such node).
+//
+// Like [sort.Search], the predicate must be monotone:
+// false for a (possibly empty) prefix of the keys in this tree,
+// and true for the remaining ones.
+// Only O(log n) nodes are tested.
+func (tree *Tree[K, V]) Search(pred func(node *Tree[K, V]) bool) (node *Tree[K, V], index int) {
+	i := 0
+	for tree != nil {
+		if pred(tree) {
+			node = tree
+			tree = tree.left
+		} else {
+			i += tree.left.Len() + 1
+			tree = tree.right
+		}
+	}
+	return node, i
+}
+
+// SearchFunc descends this tree guided by f, and returns the node where
+// the search stops and its index (or nil and the index where it would be).
+//
+// The callback f is called with each subtree visited, starting at the root,
+// and returns -1 to continue to the left subtree,
+// +1 to continue to the right subtree, or 0 to stop at its root.
+// Since f receives the subtree, it can use its length or other aggregates
+// to decide which way to go.
+func (tree *Tree[K, V]) SearchFunc(f func(subtree *Tree[K, V]) int) (node *Tree[K, V], index int) {
+	i := 0
+	for tree != nil {
+		switch f(tree) {
+		case -1:
+			tree = tree.left
+		case +1:
+			i += tree.left.Len() + 1
+			tree = tree.right
+		default:
+			return tree, i + tree.left.Len()
+		}
+	}
+	return nil, i
+}
@@ -1,6 +1,9 @@
 package aa
 
-import "testing"
+import (
+	"cmp"
+	"testing"
+)
 
 func TestTree_Select(t *testing.T) {
 	var tt *Tree[int, string]
@@ -44,3 +47,62 @@ func TestTree_Rank(t *testing.T) {
 		t.Error(i)
 	}
 }
+
+func TestTree_Search(t *testing.T) {
+	var tt *Tree[int, int]
+	for i := range 10 {
+		tt = tt.Put(i, 10*i)
+	}
+
+	for i := range 11 {
+		n, j := tt.Search(func(node *Tree[int, int]) bool {
+			return node.Value() >= 10*i-5
+		})
+		if j != i {
+			t.Errorf("%d ≠ %d", j, i)
+		}
+		if n != tt.Select(i) {
+			t.Errorf("%p ≠ %p", n, tt.Select(i))
+		}
+	}
+
+	if n, j := (*Tree[int, int])(nil).Search(nil); n != nil || j != 0 {
+		t.Error(n, j)
+	}
+}
+
+func TestTree_SearchFunc(t *testing.T) {
+	var tt *Tree[int, int]
+	for i := range 10 {
+		tt = tt.Put(2*i, i)
+	}
+
+	for i := range 10 {
+		// Reimplement Select.
+		k := i
+		n, j := tt.SearchFunc(func(subtree *Tree[int, int]) int {
+			l := subtree.Left().Len()
+			switch {
+			case k < l:
+				return -1
+			case k > l:
+				k -= l + 1
+				return +1
+			}
+			return 0
+		})
+		if n.Key() != 2*i || j != i {
+			t.Errorf("%d, %d", n.Key(), j)
+		}
+	}
+
+	// Reimplement Rank.
+	for i := range 20 {
+		n, j := tt.SearchFunc(func(subtree *Tree[int, int]) int {
+			return cmp.Compare(i, subtree.Key())
+		})
+		if j != tt.Rank(i) || (n != nil) != tt.Has(i) {
+			t.Errorf("%d: %d", i, j)
+		}
+	}
+}
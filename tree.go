@@ -141,15 +141,24 @@ func (tree *Tree[K, V]) Ceil(key K) *Tree[K, V] {
 // Get retrieves the value for a given key;
 // found indicates whether key exists in this tree.
 func (tree *Tree[K, V]) Get(key K) (value V, found bool) {
+	if node := tree.lookup(key); node != nil {
+		return node.value, true
+	}
+	return // zero, false
+}
+
+// lookup finds key in this tree, and returns the node for that key,
+// or nil if key is not found.
+func (tree *Tree[K, V]) lookup(key K) *Tree[K, V] {
 	// Floor uses 2-way search, which is faster for strings:
 	//   https://go.dev/issue/71270
 	//   https://user.it.uu.se/~arnea/ps/searchproc.pdf
 	// Both Floor/Ceil work; Floor is faster since AA trees lean right.
 	node := tree.Floor(key)
 	if node != nil && (key == node.key || key != key) {
-		return node.value, true
+		return node
 	}
-	return // zero, false
+	return nil
 }
 
 // Has reports whether key exists in this tree.
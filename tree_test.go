@@ -379,3 +379,31 @@ func BenchmarkAddGetDelete(b *testing.B) {
 		tt = tt.Delete(r.Intn(n))
 	}
 }
+
+func BenchmarkFixup(b *testing.B) {
+	// Every path copy calls fixup;
+	// only weighted trees pay to sum their totals.
+	b.Run("plain", func(b *testing.B) {
+		benchmarkFixup(b, func(i int) int { return i },
+			func(tt *Tree[int, int]) *Tree[int, int] { return tt })
+	})
+	b.Run("weighted", func(b *testing.B) {
+		benchmarkFixup(b, func(i int) weighted[int] { return weighted[int]{val: i, weight: 1} },
+			reweigh[int, int])
+	})
+}
+
+func benchmarkFixup[V any](b *testing.B, value func(int) V, after func(*Tree[int, V]) *Tree[int, V]) {
+	const n = 1 << 12
+	var tt *Tree[int, V]
+	for i := range n {
+		tt = after(tt.Put(i, value(i)))
+	}
+
+	r := rand.New(rand.NewSource(42))
+	b.ResetTimer()
+	for range b.N {
+		k := r.Intn(n)
+		tt = after(tt.Put(k, value(k)))
+	}
+}
@@ -0,0 +1,167 @@
+package aa
+
+import "cmp"
+
+// Undo is an undo/redo stack of tree versions.
+//
+// Since trees are immutable, every version shares
+// most of its nodes with its neighbouring versions.
+type Undo[K cmp.Ordered, V any] struct {
+	versions []*Tree[K, V]
+	current  int
+	depth    int
+	pending  *Tree[K, V]
+	begun    []*Tree[K, V] // the pending tree at each Begin
+}
+
+// NewUndo creates an undo stack starting at tree,
+// that retains at most depth undo steps
+// (or an unlimited number, if depth ≤ 0).
+func NewUndo[K cmp.Ordered, V any](tree *Tree[K, V], depth int) *Undo[K, V] {
+	return &Undo[K, V]{
+		versions: []*Tree[K, V]{tree},
+		depth:    depth,
+	}
+}
+
+// Tree returns the current version of the tree,
+// including any changes made by an uncommitted transaction.
+func (u *Undo[K, V]) Tree() *Tree[K, V] {
+	if len(u.begun) > 0 {
+		return u.pending
+	}
+	return u.versions[u.current]
+}
+
+// Do calls f with the current version of the tree,
+// and records the returned tree as a new version.
+//
+// If f returns the current version unmodified, no version is recorded.
+// Inside a transaction, versions are recorded when it commits.
+// Recording a version discards all redo steps.
+func (u *Undo[K, V]) Do(f func(*Tree[K, V]) *Tree[K, V]) {
+	if len(u.begun) > 0 {
+		u.pending = f(u.pending)
+		return
+	}
+	u.push(f(u.versions[u.current]))
+}
+
+func (u *Undo[K, V]) push(tree *Tree[K, V]) {
+	if tree == u.versions[u.current] {
+		return
+	}
+	u.versions = append(u.versions[:u.current+1], tree)
+	clear(u.versions[len(u.versions):cap(u.versions)])
+	if u.depth > 0 && len(u.versions) > u.depth+1 {
+		n := len(u.versions) - (u.depth + 1)
+		u.versions = append(u.versions[:0], u.versions[n:]...)
+		clear(u.versions[len(u.versions):cap(u.versions)])
+	}
+	u.current = len(u.versions) - 1
+}
+
+// Begin starts a transaction: all changes made by Do
+// until the matching call to Commit are grouped into a single version.
+//
+// Transactions can be nested; only the outermost Commit records a version.
+func (u *Undo[K, V]) Begin() {
+	if len(u.begun) == 0 {
+		u.pending = u.versions[u.current]
+	}
+	u.begun = append(u.begun, u.pending)
+}
+
+// Commit ends a transaction started by Begin.
+//
+// Note: calling Commit outside a transaction causes a runtime panic.
+func (u *Undo[K, V]) Commit() {
+	if len(u.begun) == 0 {
+		panic("commit outside a transaction")
+	}
+	u.begun = u.begun[:len(u.begun)-1]
+	if len(u.begun) == 0 {
+		pending := u.pending
+		u.pending = nil
+		u.push(pending)
+	}
+}
+
+// Rollback discards all changes made by the current transaction,
+// including any nested transactions, and ends it.
+// Changes made by enclosing transactions are kept.
+//
+// Note: calling Rollback outside a transaction causes a runtime panic.
+func (u *Undo[K, V]) Rollback() {
+	if len(u.begun) == 0 {
+		panic("rollback outside a transaction")
+	}
+	u.pending = u.begun[len(u.begun)-1]
+	u.begun[len(u.begun)-1] = nil
+	u.begun = u.begun[:len(u.begun)-1]
+	if len(u.begun) == 0 {
+		u.pending = nil
+	}
+}
+
+// Undo reverts to the previous version,
+// and reports whether there was one to revert to.
+// Undo does nothing inside a transaction.
+func (u *Undo[K, V]) Undo() bool {
+	if len(u.begun) > 0 || u.current == 0 {
+		return false
+	}
+	u.current--
+	return true
+}
+
+// Redo reapplies the version reverted by Undo,
+// and reports whether there was one to reapply.
+// Redo does nothing inside a transaction.
+func (u *Undo[K, V]) Redo() bool {
+	if len(u.begun) > 0 || u.current == len(u.versions)-1 {
+		return false
+	}
+	u.current++
+	return true
+}
+
+// CanUndo reports how many versions Undo can revert.
+func (u *Undo[K, V]) CanUndo() int {
+	return u.current
+}
+
+// CanRedo reports how many versions Redo can reapply.
+func (u *Undo[K, V]) CanRedo() int {
+	return len(u.versions) - 1 - u.current
+}
+
+// Nodes returns the number of distinct nodes pinned by this undo stack,
+// counting the nodes shared between neighbouring versions only once.
+//
+// The cost is proportional to the number of nodes not shared
+// between neighbouring versions, times the level of the trees.
+func (u *Undo[K, V]) Nodes() int {
+	n := u.versions[0].Len()
+	for i := 1; i < len(u.versions); i++ {
+		n += Unshared(u.versions[i], u.versions[i-1])
+	}
+	return n
+}
+
+// Unshared returns the number of nodes in t1 that are not shared with t2.
+//
+// Shared subtrees are skipped wholesale,
+// so the cost is proportional to the number of unshared nodes,
+// times the level of t2.
+func Unshared[K cmp.Ordered, V any](t1, t2 *Tree[K, V]) int {
+	if t1 == nil {
+		return 0
+	}
+	// A node shared with t2 is the node found by searching t2 for its key.
+	node := t2.Floor(t1.key)
+	if node == t1 {
+		return 0
+	}
+	return 1 + Unshared(t1.left, t2) + Unshared(t1.right, t2)
+}
@@ -0,0 +1,131 @@
+package aa
+
+import "testing"
+
+func TestUndo(t *testing.T) {
+	var tt *Tree[int, string]
+	u := NewUndo(tt, 2)
+
+	if u.Undo() || u.Redo() {
+		t.Error()
+	}
+
+	u.Do(func(t *Tree[int, string]) *Tree[int, string] { return t.Put(1, "one") })
+	u.Do(func(t *Tree[int, string]) *Tree[int, string] { return t.Put(2, "two") })
+	u.Do(func(t *Tree[int, string]) *Tree[int, string] { return t.Add(2) })
+	if n := u.CanUndo(); n != 2 {
+		t.Error(n)
+	}
+
+	if !u.Undo() || u.Tree().Has(2) {
+		t.Error()
+	}
+	if !u.Redo() || !u.Tree().Has(2) {
+		t.Error()
+	}
+
+	// Depth is bounded.
+	u.Do(func(t *Tree[int, string]) *Tree[int, string] { return t.Put(3, "three") })
+	if n := u.CanUndo(); n != 2 {
+		t.Error(n)
+	}
+	u.Undo()
+	u.Undo()
+	if u.Undo() || u.Tree().Len() != 1 {
+		t.Error(u.Tree().Len())
+	}
+
+	// Doing discards redo steps.
+	u.Do(func(t *Tree[int, string]) *Tree[int, string] { return t.Put(4, "four") })
+	if u.Redo() || u.CanRedo() != 0 {
+		t.Error()
+	}
+}
+
+func TestUndo_transaction(t *testing.T) {
+	u := NewUndo[int, struct{}](nil, 0)
+
+	u.Begin()
+	u.Do(func(t *Tree[int, struct{}]) *Tree[int, struct{}] { return t.Add(1) })
+	u.Begin()
+	u.Do(func(t *Tree[int, struct{}]) *Tree[int, struct{}] { return t.Add(2) })
+	u.Commit()
+	if u.Undo() || u.Tree().Len() != 2 {
+		t.Error()
+	}
+	u.Commit()
+
+	if n := u.CanUndo(); n != 1 {
+		t.Error(n)
+	}
+
+	u.Begin()
+	u.Do(func(t *Tree[int, struct{}]) *Tree[int, struct{}] { return t.Add(3) })
+	u.Rollback()
+	if u.Tree().Has(3) || u.CanUndo() != 1 {
+		t.Error()
+	}
+
+	u.Undo()
+	if u.Tree() != nil {
+		t.Error()
+	}
+
+	defer func() { _ = recover() }()
+	u.Commit()
+	t.Error()
+}
+
+func TestUndo_nestedRollback(t *testing.T) {
+	add := func(k int) func(*Tree[int, struct{}]) *Tree[int, struct{}] {
+		return func(t *Tree[int, struct{}]) *Tree[int, struct{}] { return t.Add(k) }
+	}
+
+	u := NewUndo[int, struct{}](nil, 0)
+	u.Begin()
+	u.Do(add(1))
+	u.Begin()
+	u.Do(add(2))
+	u.Begin()
+	u.Do(add(3))
+	u.Rollback()
+	if !u.Tree().Has(2) || u.Tree().Has(3) {
+		t.Error(u.Tree().Collect())
+	}
+	u.Rollback()
+	if !u.Tree().Has(1) || u.Tree().Has(2) {
+		t.Error(u.Tree().Collect())
+	}
+	u.Do(add(4))
+	u.Commit()
+	if got := u.Tree().Len(); got != 2 || !u.Tree().Has(4) || u.CanUndo() != 1 {
+		t.Error(u.Tree().Collect())
+	}
+
+	u.Begin()
+	u.Do(add(5))
+	u.Rollback()
+	if u.Tree().Has(5) || u.CanUndo() != 1 {
+		t.Error(u.Tree().Collect())
+	}
+}
+
+func TestUndo_Nodes(t *testing.T) {
+	tt := MakeSet(0, 1, 2, 3, 4, 5, 6)
+	u := NewUndo(tt, 0)
+
+	if n := u.Nodes(); n != 7 {
+		t.Error(n)
+	}
+
+	u.Do(func(t *Tree[int, struct{}]) *Tree[int, struct{}] { return t.Add(7) })
+	if n, m := u.Nodes(), 7+Unshared(u.Tree(), tt); n != m || m <= 7 || m >= 14 {
+		t.Error(n, m)
+	}
+	if n := Unshared(tt, tt); n != 0 {
+		t.Error(n)
+	}
+	if n := Unshared(tt, nil); n != 7 {
+		t.Error(n)
+	}
+}
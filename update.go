@@ -0,0 +1,73 @@
+package aa
+
+import "cmp"
+
+// Swap returns a modified tree with key set to value,
+// and the previous value for key;
+// existed indicates whether key existed in this tree.
+func (tree *Tree[K, V]) Swap(key K, value V) (_ *Tree[K, V], old V, existed bool) {
+	tree = tree.Patch(key, func(node *Tree[K, V]) (V, bool) {
+		if node != nil {
+			old, existed = node.value, true
+		}
+		return value, true
+	})
+	return tree, old, existed
+}
+
+// PutIfAbsent returns a (possibly) modified tree with key set to value,
+// unless key already exists in this tree.
+// It also returns the value for key in the returned tree;
+// loaded is true if that value was already in this tree.
+func (tree *Tree[K, V]) PutIfAbsent(key K, value V) (_ *Tree[K, V], actual V, loaded bool) {
+	tree = tree.Patch(key, func(node *Tree[K, V]) (V, bool) {
+		if node != nil {
+			value, loaded = node.value, true
+		}
+		return value, !loaded
+	})
+	return tree, value, loaded
+}
+
+// Upsert returns a modified tree with key set to the value returned by update,
+// which is called with the current value for key, and whether key exists.
+func (tree *Tree[K, V]) Upsert(key K, update func(value V, found bool) V) *Tree[K, V] {
+	return tree.Patch(key, func(node *Tree[K, V]) (value V, _ bool) {
+		if node != nil {
+			return update(node.value, true), true
+		}
+		return update(value, false), true
+	})
+}
+
+// LoadAndDelete returns a (possibly) modified tree with key removed from it,
+// and the removed value; found indicates whether key existed in this tree.
+func (tree *Tree[K, V]) LoadAndDelete(key K) (_ *Tree[K, V], value V, found bool) {
+	tree = tree.delete(key, func(node *Tree[K, V]) bool {
+		value, found = node.value, true
+		return true
+	})
+	return tree, value, found
+}
+
+// CompareAndSwap returns a (possibly) modified tree with key set to new,
+// if and only if key exists in tree with value old;
+// swapped indicates whether the tree was modified.
+func CompareAndSwap[K cmp.Ordered, V comparable](tree *Tree[K, V], key K, old, new V) (_ *Tree[K, V], swapped bool) {
+	tree = tree.Patch(key, func(node *Tree[K, V]) (V, bool) {
+		swapped = node != nil && node.value == old
+		return new, swapped
+	})
+	return tree, swapped
+}
+
+// CompareAndDelete returns a (possibly) modified tree with key removed from it,
+// if and only if key exists in tree with value old;
+// deleted indicates whether the tree was modified.
+func CompareAndDelete[K cmp.Ordered, V comparable](tree *Tree[K, V], key K, old V) (_ *Tree[K, V], deleted bool) {
+	tree = tree.delete(key, func(node *Tree[K, V]) bool {
+		deleted = node.value == old
+		return deleted
+	})
+	return tree, deleted
+}
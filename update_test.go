@@ -0,0 +1,97 @@
+package aa
+
+import "testing"
+
+func TestTree_Swap(t *testing.T) {
+	var tt *Tree[int, string]
+	tt, old, ok := tt.Swap(1, "one")
+	if ok || old != "" || !tt.Has(1) {
+		t.Error(old, ok)
+	}
+	tt, old, ok = tt.Swap(1, "ONE")
+	if !ok || old != "one" {
+		t.Error(old, ok)
+	}
+	if v, _ := tt.Get(1); v != "ONE" {
+		t.Error(v)
+	}
+}
+
+func TestTree_PutIfAbsent(t *testing.T) {
+	var tt *Tree[int, string]
+	tt, v, loaded := tt.PutIfAbsent(1, "one")
+	if loaded || v != "one" {
+		t.Error(v, loaded)
+	}
+
+	a, v, loaded := tt.PutIfAbsent(1, "ONE")
+	if !loaded || v != "one" {
+		t.Error(v, loaded)
+	}
+	if a != tt {
+		t.Errorf("%p ≠ %p", a, tt)
+	}
+}
+
+func TestTree_Upsert(t *testing.T) {
+	var tt *Tree[string, int]
+	inc := func(v int, ok bool) int {
+		if ok {
+			return v + 1
+		}
+		return 100
+	}
+
+	tt = tt.Upsert("a", inc).Upsert("b", inc).Upsert("a", inc)
+	if v, _ := tt.Get("a"); v != 101 {
+		t.Error(v)
+	}
+	if v, _ := tt.Get("b"); v != 100 {
+		t.Error(v)
+	}
+}
+
+func TestTree_LoadAndDelete(t *testing.T) {
+	var tt *Tree[int, string]
+	tt = tt.Put(1, "one").Put(2, "two").Put(3, "three")
+
+	a, v, ok := tt.LoadAndDelete(4)
+	if ok || v != "" || a != tt {
+		t.Error(v, ok)
+	}
+
+	tt, v, ok = tt.LoadAndDelete(2)
+	if !ok || v != "two" || tt.Has(2) {
+		t.Error(v, ok)
+	}
+	tt.check()
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	var tt *Tree[int, string]
+	tt = tt.Put(1, "one")
+
+	if a, ok := CompareAndSwap(tt, 1, "two", "TWO"); ok || a != tt {
+		t.Error(ok)
+	}
+	if a, ok := CompareAndSwap(tt, 2, "", "TWO"); ok || a != tt {
+		t.Error(ok)
+	}
+	if a, ok := CompareAndSwap(tt, 1, "one", "ONE"); !ok {
+		t.Error(ok)
+	} else if v, _ := a.Get(1); v != "ONE" {
+		t.Error(v)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	var tt *Tree[int, string]
+	tt = tt.Put(1, "one").Put(2, "two")
+
+	if a, ok := CompareAndDelete(tt, 1, "two"); ok || a != tt {
+		t.Error(ok)
+	}
+	if a, ok := CompareAndDelete(tt, 1, "one"); !ok || a.Has(1) {
+		t.Error(ok)
+	}
+}
@@ -0,0 +1,95 @@
+package aa
+
+import "cmp"
+
+// weighted is a value augmented with the total weight of its subtree,
+// so that trees of weighted values support weighted order statistics.
+//
+// Core tree operations know nothing about totals:
+// they copy nodes, values and all, whenever they change a subtree.
+// So every tree of weighted values must be passed through reweigh
+// after it's modified, and before it's used or shared.
+type weighted[T any] struct {
+	val    T
+	weight int // of this node, positive
+	total  int // of this subtree, or zero if not yet summed
+
+	// The values of the children the total was summed from.
+	left, right *weighted[T]
+}
+
+// reweigh sums the totals of nodes in tree created or modified since
+// the last reweigh, and returns tree.
+//
+// A node needs summing if its value is new (a zero total),
+// or if its children are not the ones its total was summed from.
+// Other nodes, and their subtrees, are shared with already summed trees,
+// so only nodes created by the modification are visited and updated.
+func reweigh[K cmp.Ordered, T any](tree *Tree[K, weighted[T]]) *Tree[K, weighted[T]] {
+	if tree == nil {
+		return nil
+	}
+	w := &tree.value
+	left, right := weightOf(tree.left), weightOf(tree.right)
+	if w.total > 0 && w.left == left && w.right == right {
+		return tree
+	}
+
+	reweigh(tree.left)
+	reweigh(tree.right)
+	w.total = w.weight + totalWeight(tree.left) + totalWeight(tree.right)
+	w.left, w.right = left, right
+	return tree
+}
+
+func weightOf[K cmp.Ordered, T any](tree *Tree[K, weighted[T]]) *weighted[T] {
+	if tree == nil {
+		return nil
+	}
+	return &tree.value
+}
+
+// totalWeight returns the total weight of the nodes in tree.
+func totalWeight[K cmp.Ordered, T any](tree *Tree[K, weighted[T]]) int {
+	if tree == nil {
+		return 0
+	}
+	return tree.value.total
+}
+
+// weightRank returns the total weight of the nodes in tree with keys less than key.
+func weightRank[K cmp.Ordered, T any](tree *Tree[K, weighted[T]], key K) int {
+	k := 0
+	for tree != nil {
+		switch cmp.Compare(key, tree.key) {
+		case -1:
+			tree = tree.left
+
+		case +1:
+			k += totalWeight(tree.left) + tree.value.weight
+			tree = tree.right
+
+		default:
+			return k + totalWeight(tree.left)
+		}
+	}
+	return k
+}
+
+// weightSelect finds the node at weighted index i of tree and returns it,
+// and the index into its weight (or nil if i is out of range).
+func weightSelect[K cmp.Ordered, T any](tree *Tree[K, weighted[T]], i int) (*Tree[K, weighted[T]], int) {
+	for tree != nil && i >= 0 {
+		p := totalWeight(tree.left)
+		switch {
+		case i < p:
+			tree = tree.left
+		case i < p+tree.value.weight:
+			return tree, i - p
+		default:
+			i -= p + tree.value.weight
+			tree = tree.right
+		}
+	}
+	return nil, 0
+}